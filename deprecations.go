@@ -0,0 +1,166 @@
+package kubelint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// KubernetesVersion is a minor release of Kubernetes, eg 1.22. Patch releases don't add or remove APIs.
+// The zero value stands for the newest release kubelint knows about.
+type KubernetesVersion struct {
+	Major int
+	Minor int
+}
+
+var kubernetesVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)(\.\d+)?([-+].*)?$`)
+
+// ParseKubernetesVersion parses a version the way clusters and tools write them, eg "1.22", "v1.22.3" or "v1.21.5-eks-bc4871b".
+func ParseKubernetesVersion(version string) (KubernetesVersion, error) {
+	match := kubernetesVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return KubernetesVersion{}, fmt.Errorf("%q isn't a Kubernetes version, eg 1.22 or v1.22.3", version)
+	}
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return KubernetesVersion{}, fmt.Errorf("%q isn't a Kubernetes version: %s", version, err)
+	}
+	minor, err := strconv.Atoi(match[2])
+	if err != nil {
+		return KubernetesVersion{}, fmt.Errorf("%q isn't a Kubernetes version: %s", version, err)
+	}
+	return KubernetesVersion{Major: major, Minor: minor}, nil
+}
+
+// MustParseKubernetesVersion is like ParseKubernetesVersion, but panics if the version can't be parsed.
+func MustParseKubernetesVersion(version string) KubernetesVersion {
+	v, err := ParseKubernetesVersion(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v KubernetesVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// IsZero tells you if the version is the zero value, ie the newest release kubelint knows about.
+func (v KubernetesVersion) IsZero() bool {
+	return v == KubernetesVersion{}
+}
+
+// AtLeast tells you if the version is the same as or later than the other one. The zero value is later than every version.
+func (v KubernetesVersion) AtLeast(other KubernetesVersion) bool {
+	if v.IsZero() {
+		return true
+	}
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// APIDeprecation records that an API version of a kind of resource was deprecated, and the release it was (or will be) removed in.
+type APIDeprecation struct {
+	APIVersion   string            // eg extensions/v1beta1
+	Kind         string            // eg Deployment
+	DeprecatedIn KubernetesVersion // the release that deprecated it
+	RemovedIn    KubernetesVersion // the release that stopped serving it
+	MigrateTo    string            // what to use instead, usually an API version, eg apps/v1
+}
+
+// APIDeprecations is the table of deprecated and removed APIs the rules made by NewInterdependentRemovedAPIVersionsRule
+// and NewInterdependentDeprecatedAPIVersionsRule use by default, from https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+// The CustomResourceDefinition, APIService and flowcontrol.apiserver.k8s.io entries never match for now: the client-go kubelint
+// is built against can't decode those API versions, so reading them fails before any rule sees them.
+var APIDeprecations = []APIDeprecation{
+	// 1.16
+	{"extensions/v1beta1", "Deployment", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", KubernetesVersion{1, 10}, KubernetesVersion{1, 16}, "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"apps/v1beta2", "Deployment", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", KubernetesVersion{1, 9}, KubernetesVersion{1, 16}, "apps/v1"},
+	// 1.22
+	{"extensions/v1beta1", "Ingress", KubernetesVersion{1, 14}, KubernetesVersion{1, 22}, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", KubernetesVersion{1, 17}, KubernetesVersion{1, 22}, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", KubernetesVersion{1, 17}, KubernetesVersion{1, 22}, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", KubernetesVersion{1, 17}, KubernetesVersion{1, 22}, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", KubernetesVersion{1, 17}, KubernetesVersion{1, 22}, "rbac.authorization.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "LocalSubjectAccessReview", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectAccessReview", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectRulesReview", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "authorization.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", KubernetesVersion{1, 16}, KubernetesVersion{1, 22}, "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", KubernetesVersion{1, 16}, KubernetesVersion{1, 22}, "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", KubernetesVersion{1, 16}, KubernetesVersion{1, 22}, "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "coordination.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", KubernetesVersion{1, 14}, KubernetesVersion{1, 22}, "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", KubernetesVersion{1, 19}, KubernetesVersion{1, 22}, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", KubernetesVersion{1, 17}, KubernetesVersion{1, 22}, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", KubernetesVersion{1, 6}, KubernetesVersion{1, 22}, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", KubernetesVersion{1, 13}, KubernetesVersion{1, 22}, "storage.k8s.io/v1"},
+	// 1.25
+	{"batch/v1beta1", "CronJob", KubernetesVersion{1, 21}, KubernetesVersion{1, 25}, "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", KubernetesVersion{1, 21}, KubernetesVersion{1, 25}, "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", KubernetesVersion{1, 19}, KubernetesVersion{1, 25}, "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", KubernetesVersion{1, 22}, KubernetesVersion{1, 25}, "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", KubernetesVersion{1, 21}, KubernetesVersion{1, 25}, "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", KubernetesVersion{1, 21}, KubernetesVersion{1, 25}, "Pod Security Admission (there's no replacement API)"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", KubernetesVersion{1, 20}, KubernetesVersion{1, 25}, "node.k8s.io/v1"},
+	// 1.26
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", KubernetesVersion{1, 23}, KubernetesVersion{1, 26}, "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", KubernetesVersion{1, 23}, KubernetesVersion{1, 26}, "flowcontrol.apiserver.k8s.io/v1beta3"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", KubernetesVersion{1, 23}, KubernetesVersion{1, 26}, "flowcontrol.apiserver.k8s.io/v1beta3"},
+	// 1.27
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", KubernetesVersion{1, 24}, KubernetesVersion{1, 27}, "storage.k8s.io/v1"},
+	// 1.29
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", KubernetesVersion{1, 26}, KubernetesVersion{1, 29}, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", KubernetesVersion{1, 26}, KubernetesVersion{1, 29}, "flowcontrol.apiserver.k8s.io/v1"},
+	// 1.32
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", KubernetesVersion{1, 29}, KubernetesVersion{1, 32}, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", KubernetesVersion{1, 29}, KubernetesVersion{1, 32}, "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// apiDeprecationViolations gives you a violation for each resource in the graph whose apiVersion and kind are in the table
+// (APIDeprecations if it's nil) and that describe has a message for. describe returns an empty string to leave a resource out.
+func apiDeprecationViolations(graph *ResourceGraph, deprecations []APIDeprecation, describe func(*Resource, APIDeprecation) string) []*Violation {
+	if deprecations == nil {
+		deprecations = APIDeprecations
+	}
+	var violations []*Violation
+	for _, resource := range graph.Resources() {
+		deprecation, found := findDeprecation(deprecations, resource.TypeInfo.GetAPIVersion(), resource.TypeInfo.GetKind())
+		if !found {
+			continue
+		}
+		if message := describe(resource, deprecation); message != "" {
+			violations = append(violations, &Violation{
+				Resources: []*Resource{resource},
+				Message:   message,
+			})
+		}
+	}
+	return violations
+}
+
+// findDeprecation looks up the API version and kind in the table.
+func findDeprecation(deprecations []APIDeprecation, apiVersion string, kind string) (APIDeprecation, bool) {
+	for _, deprecation := range deprecations {
+		if deprecation.APIVersion == apiVersion && deprecation.Kind == kind {
+			return deprecation, true
+		}
+	}
+	return APIDeprecation{}, false
+}
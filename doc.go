@@ -85,13 +85,15 @@ The rules are evaluated in topological sort order to make sure that prerequisite
 
 If you want to apply fixes, you can write the result to whatever file you want, or just output the result to a bytes slice.
 You can't modify the file in-place so to speak, since we perform the analysis on in-memory representations of the contents of
-the original files, but you can still simulate an in-place fixer if you overwrite the original file with the result of l.ApplyFixes().
+the original files, but you can still simulate an in-place fixer if you overwrite the original file with the result of ApplyFixes.
+Fixes belong to a Session, so lint in one to be able to apply them.
 
 	l := kubelint.NewDefaultLinter()
 	// ... add some rules
-	results, errors := l.Lint(filepaths...)
+	session := l.NewSession()
+	results, errors := session.Lint(filepaths...)
 	// you can apply the fixes that are suggested
-	resources, fixDescriptions := l.ApplyFixes()
+	resources, fixDescriptions := session.ApplyFixes()
 	bytes, errs := kubelint.Write(resources...)
 	fmt.Printf("%s\n", string(bytes))
 
@@ -99,6 +101,34 @@ Also notice that you can report the fixes that have been applied.
 	for _, description := range fixDescriptions {
 		fmt.Printf("X %s\n", description)
 	}
+ApplyFixes runs each fix exactly once. If one fix might break another rule, or you aren't sure your fix really
+satisfies its rule, use FixUntilStable. It lints the resources again after every round of fixes, stops once nothing else
+can be fixed, and tells you which fixes didn't stick and which fixes kept undoing each other.
+
+	report := session.FixUntilStable(kubelint.DefaultMaxFixIterations)
+	for _, result := range report.Ineffective {
+		fmt.Printf("%s still fails after being fixed: %s\n", result.RuleID, result.Message)
+	}
+	if len(report.Oscillating) != 0 {
+		fmt.Printf("These rules keep undoing each other's fixes: %v\n", report.Oscillating)
+	}
+The linter only holds the rules, and the session owns the resources, results and pending fixes, so one linter can be shared
+by many goroutines as long as each of them starts its own session. Lint, LintBytes and LintFile on the linter run in a session
+of their own that's thrown away afterwards, for when you only want the results.
+
+If one of your rules panics, the linter recovers and returns a *RuleError naming the rule and the resource it was checking.
+Use LintContext (or LintBytesContext, LintFileContext) to be able to cancel a run, and SetRuleTimeout to give up on rules that take too long.
+
+	l.SetRuleTimeout(time.Second)
+	results, errors := l.LintContext(ctx, filepaths...)
+
+To lint something too big to hold in memory, like the helm template output of a whole platform, use LintStream.
+It reads one document at a time and reports each result as soon as it's found, but keeps no resources, so there are no fixes to apply.
+
+	errors := l.LintStream(ctx, os.Stdin, "stdin", func(result *kubelint.Result) {
+		fmt.Println(result.Message)
+	})
+
 If anything goes wrong with your linter implementation, you can attempt to debug by creating a logrus.logger instance
 and passing it to the constructor of the linter object.
 
@@ -0,0 +1,170 @@
+package kubelint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultMaxFixIterations is the number of fix passes FixUntilStable makes when it isn't given a limit.
+const DefaultMaxFixIterations = 10
+
+// FixReport describes what happened while FixUntilStable was repeatedly fixing and linting the resources.
+type FixReport struct {
+	Resources   []*Resource // the resources after all the fixes were applied
+	Applied     []string    // the descriptions of every fix that was applied, in the order they were applied
+	Ineffective []*Result   // results from the final pass for rules whose fix was applied, but which still fail
+	Oscillating []RuleID    // rules whose fixes kept undoing each other, returning the resources to an earlier state
+	Results     []*Result   // the results of the final lint pass, ie what couldn't be fixed
+	Errors      []error     // the errors from the final lint pass
+	Iterations  int         // the number of passes in which at least one fix was applied
+	Converged   bool        // true if the loop stopped because there were no more fixes that could be applied
+}
+
+// appliedFix keeps track of a fix that was successfully applied to some resources,
+// so that the rule can be checked again on the next pass.
+type appliedFix struct {
+	ID          RuleID
+	Resources   []*YamlDerivedResource
+	Description string
+}
+
+// FixUntilStable applies the pending fixes, lints every unit again and repeats until
+// no more fixes can be applied, the resources return to a state they were already in,
+// or maxIterations passes have been made (DefaultMaxFixIterations if maxIterations <= 0).
+// Unlike ApplyFixes, this notices fixes that don't actually satisfy their rule and fixes
+// that break the rule of another fix.
+func (s *Session) FixUntilStable(maxIterations int) *FixReport {
+	return s.FixUntilStableContext(context.Background(), maxIterations)
+}
+
+// FixUntilStableContext is like FixUntilStable, but gives up once the context is done.
+// Errors from fixes that panicked are included in the report's Errors.
+func (s *Session) FixUntilStableContext(ctx context.Context, maxIterations int) *FixReport {
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxFixIterations
+	}
+	report := &FixReport{}
+	// start again from the current state of the resources, in case some fixes were already applied
+	report.Results, report.Errors = s.relint(ctx)
+	seen := map[string]int{s.fingerprint(): 0}
+	var history [][]*appliedFix
+	for report.Iterations < maxIterations {
+		applied, fixErrors := s.applyFixes(ctx)
+		if ctx.Err() != nil {
+			report.Errors = append(report.Errors, fixErrors...)
+			break
+		}
+		if len(applied) == 0 {
+			report.Errors = append(report.Errors, fixErrors...)
+			report.Converged = true
+			break
+		}
+		report.Iterations++
+		history = append(history, applied)
+		for _, fix := range applied {
+			s.logger.Debugln("Applied fix for", fix.ID)
+			report.Applied = append(report.Applied, fix.Description)
+		}
+		report.Results, report.Errors = s.relint(ctx)
+		report.Errors = append(report.Errors, fixErrors...)
+		fingerprint := s.fingerprint()
+		if previous, found := seen[fingerprint]; found {
+			if previous != report.Iterations-1 {
+				// we've come back around to an earlier state, so whatever was fixed since then got undone
+				report.Oscillating = fixedRuleIDs(history[previous:])
+				s.logger.Debugf("Fixes are oscillating: %v\n", report.Oscillating)
+			}
+			// the fixes didn't change anything, applying them again won't either
+			break
+		}
+		seen[fingerprint] = report.Iterations
+	}
+	report.Ineffective = ineffectiveFixResults(history, report.Results)
+	report.Resources = s.resources
+	return report
+}
+
+// relint forgets about the pending fixes and lints every unit again
+// so that the pending fixes match the current state of the resources.
+func (s *Session) relint(ctx context.Context) ([]*Result, []error) {
+	s.fixes = nil
+	s.interdependentFixes = nil
+	var results []*Result
+	var errors []error
+	for _, unit := range s.units {
+		r, errs := s.lintYamlDerivedResources(ctx, unit)
+		results = append(results, r...)
+		errors = append(errors, errs...)
+	}
+	for _, resource := range s.loose {
+		outcome := s.lintResource(ctx, resource)
+		results = append(results, outcome.results...)
+		s.fixes = append(s.fixes, outcome.fixes)
+		errors = append(errors, outcome.errors...)
+	}
+	s.results = results
+	return results, errors
+}
+
+// fingerprint summarises the current state of all the resources read in by the session,
+// so we can tell if applying fixes has returned them to an earlier state.
+func (s *Session) fingerprint() string {
+	hash := sha256.New()
+	for _, resource := range s.resources {
+		bytes, _ := json.Marshal(resource.Object)
+		hash.Write(bytes)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// fixedRuleIDs lists (without duplicates) the rules that had fixes applied in the given passes.
+func fixedRuleIDs(passes [][]*appliedFix) []RuleID {
+	var ids []RuleID
+	found := make(map[RuleID]bool)
+	for _, pass := range passes {
+		for _, fix := range pass {
+			if !found[fix.ID] {
+				found[fix.ID] = true
+				ids = append(ids, fix.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// ineffectiveFixResults finds the results produced by rules that had a fix applied to the same resources
+// at some point, ie the fix didn't satisfy the rule or something else broke it again.
+func ineffectiveFixResults(passes [][]*appliedFix, results []*Result) []*Result {
+	var ineffective []*Result
+	for _, result := range results {
+		for _, pass := range passes {
+			if fix := findAppliedFix(pass, result); fix != nil {
+				ineffective = append(ineffective, result)
+				break
+			}
+		}
+	}
+	return ineffective
+}
+
+// findAppliedFix returns the fix in the pass that was applied for the rule and resources of the result, if any.
+func findAppliedFix(pass []*appliedFix, result *Result) *appliedFix {
+	for _, fix := range pass {
+		if fix.ID != result.RuleID {
+			continue
+		}
+		if len(fix.Resources) == 0 && len(result.Resources) == 0 {
+			return fix
+		}
+		for _, fixed := range fix.Resources {
+			for _, resource := range result.Resources {
+				if fixed == resource {
+					return fix
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ module github.com/CoverGenius/kubelint
 go 1.13
 
 require (
-	github.com/CoverGenius/kubelint v0.0.0-20200305005400-012e0b1b63f6
 	github.com/fatih/color v1.7.0
 	github.com/sirupsen/logrus v1.4.2
 	k8s.io/api v0.17.3
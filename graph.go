@@ -0,0 +1,275 @@
+package kubelint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// EdgeType says how one resource in a ResourceGraph relates to another.
+type EdgeType string
+
+const (
+	EdgeSelects       EdgeType = "selects"         // a Service selects the pods of a workload
+	EdgeRoutesTo      EdgeType = "routes-to"       // an Ingress sends traffic to a Service
+	EdgeUsesTLSSecret EdgeType = "uses-tls-secret" // an Ingress terminates TLS with a Secret
+	EdgeBindsRole     EdgeType = "binds-role"      // a RoleBinding or ClusterRoleBinding grants a Role or ClusterRole
+	EdgeBindsSubject  EdgeType = "binds-subject"   // a RoleBinding or ClusterRoleBinding grants its role to a ServiceAccount
+	EdgeRunsAs        EdgeType = "runs-as"         // a workload's pods run as a ServiceAccount
+	EdgeReferences    EdgeType = "references"      // a workload's pods use a ConfigMap, Secret or PersistentVolumeClaim
+	EdgeScales        EdgeType = "scales"          // a HorizontalPodAutoscaler scales a workload
+	EdgeProtects      EdgeType = "protects"        // a PodDisruptionBudget covers the pods of a workload
+	EdgeIsolates      EdgeType = "isolates"        // a NetworkPolicy applies to the pods of a workload
+	EdgeTypeAny       EdgeType = ""                // matches every type of edge when looking them up
+)
+
+// graphKeySeparator joins the kind, namespace and name of a resource into the key it's indexed by.
+const graphKeySeparator = "/"
+
+// Edge is a relationship between two resources in a unit, eg a Service that selects the pods of a Deployment.
+type Edge struct {
+	Type  EdgeType
+	From  *Resource
+	To    *Resource
+	Field string // where the relationship comes from in From, if there's more than one place it could, eg env[PASSWORD].valueFrom.secretKeyRef
+}
+
+// ResourceGraph indexes the resources of a unit and the relationships between them,
+// so an interdependent rule doesn't need to scan and type assert every resource to find the ones it cares about.
+// It's built once per unit, and is read-only once built, so it's safe to share between rules.
+type ResourceGraph struct {
+	resources   []*Resource
+	byKind      map[string][]*Resource
+	byNamespace map[string][]*Resource
+	byName      map[string][]*Resource // indexed by kind/namespace/name
+	byLabel     map[string][]*Resource // indexed by key=value for every label in the resources' metadata
+	workloads   map[string][]*Resource // the resources that create pods, indexed by namespace
+	edges       []*Edge
+	from        map[*Resource][]*Edge
+	to          map[*Resource][]*Edge
+	restMapper  meta.RESTMapper // the linter's RESTMapper, used to tell which resources are namespaced
+}
+
+// NewResourceGraph indexes the resources and finds the relationships between them.
+func NewResourceGraph(resources []*Resource) *ResourceGraph {
+	g := &ResourceGraph{
+		resources:   resources,
+		byKind:      make(map[string][]*Resource),
+		byNamespace: make(map[string][]*Resource),
+		byName:      make(map[string][]*Resource),
+		byLabel:     make(map[string][]*Resource),
+		workloads:   make(map[string][]*Resource),
+		from:        make(map[*Resource][]*Edge),
+		to:          make(map[*Resource][]*Edge),
+	}
+	for _, resource := range resources {
+		kind := resource.TypeInfo.GetKind()
+		namespace := resource.Object.GetNamespace()
+		g.byKind[kind] = append(g.byKind[kind], resource)
+		g.byNamespace[namespace] = append(g.byNamespace[namespace], resource)
+		key := strings.Join([]string{kind, namespace, resource.Object.GetName()}, graphKeySeparator)
+		g.byName[key] = append(g.byName[key], resource)
+		for key, value := range resource.Object.GetLabels() {
+			g.byLabel[key+"="+value] = append(g.byLabel[key+"="+value], resource)
+		}
+		if _, _, ok := podTemplate(resource.Object); ok {
+			g.workloads[namespace] = append(g.workloads[namespace], resource)
+		}
+	}
+	for _, resource := range resources {
+		g.link(resource)
+	}
+	return g
+}
+
+// Resources gives you every resource in the unit, in the order they were read.
+func (g *ResourceGraph) Resources() []*Resource {
+	return g.resources
+}
+
+// Kind gives you the resources of the given kind, eg "Deployment".
+func (g *ResourceGraph) Kind(kind string) []*Resource {
+	return g.byKind[kind]
+}
+
+// Namespace gives you the resources in the given namespace. Cluster scoped resources (and resources without a namespace) are in "".
+func (g *ResourceGraph) Namespace(namespace string) []*Resource {
+	return g.byNamespace[namespace]
+}
+
+// Lookup gives you the resources with the given kind, namespace and name.
+// There should only be one, but if the unit declares the same object twice you'll get both.
+func (g *ResourceGraph) Lookup(kind string, namespace string, name string) []*Resource {
+	return g.byName[strings.Join([]string{kind, namespace, name}, graphKeySeparator)]
+}
+
+// Labelled gives you the resources that have the label key=value in their metadata.
+func (g *ResourceGraph) Labelled(key string, value string) []*Resource {
+	return g.byLabel[key+"="+value]
+}
+
+// Workloads gives you the resources in the namespace that create pods (including Pods themselves).
+func (g *ResourceGraph) Workloads(namespace string) []*Resource {
+	return g.workloads[namespace]
+}
+
+// SelectWorkloads gives you the workloads in the namespace whose pod template labels match the selector.
+func (g *ResourceGraph) SelectWorkloads(namespace string, selector labels.Selector) []*Resource {
+	var selected []*Resource
+	for _, workload := range g.workloads[namespace] {
+		podMetadata, _, _ := podTemplate(workload.Object)
+		if selector.Matches(labels.Set(podMetadata.Labels)) {
+			selected = append(selected, workload)
+		}
+	}
+	return selected
+}
+
+// IsNamespaced tells you if the resource should live in a namespace. The linter's RESTMapper (see Linter.SetRESTMapper)
+// is asked first, then the built-in table of cluster scoped kinds. A kind neither of them knows about is assumed to be namespaced.
+func (g *ResourceGraph) IsNamespaced(resource *Resource) bool {
+	return isNamespacedBy(g.restMapper, resource)
+}
+
+// Edges gives you every relationship in the unit.
+func (g *ResourceGraph) Edges() []*Edge {
+	return g.edges
+}
+
+// From gives you the relationships of the given type (or all of them for EdgeTypeAny) from the resource to other resources.
+func (g *ResourceGraph) From(resource *Resource, edgeType EdgeType) []*Edge {
+	return filterEdges(g.from[resource], edgeType)
+}
+
+// To gives you the relationships of the given type (or all of them for EdgeTypeAny) from other resources to the resource.
+func (g *ResourceGraph) To(resource *Resource, edgeType EdgeType) []*Edge {
+	return filterEdges(g.to[resource], edgeType)
+}
+
+func filterEdges(edges []*Edge, edgeType EdgeType) []*Edge {
+	if edgeType == EdgeTypeAny {
+		return edges
+	}
+	var filtered []*Edge
+	for _, edge := range edges {
+		if edge.Type == edgeType {
+			filtered = append(filtered, edge)
+		}
+	}
+	return filtered
+}
+
+// addEdges records a relationship from a resource to each of the targets.
+func (g *ResourceGraph) addEdges(edgeType EdgeType, from *Resource, field string, targets []*Resource) {
+	for _, to := range targets {
+		edge := &Edge{Type: edgeType, From: from, To: to, Field: field}
+		g.edges = append(g.edges, edge)
+		g.from[from] = append(g.from[from], edge)
+		g.to[to] = append(g.to[to], edge)
+	}
+}
+
+// link finds the relationships from the resource to the rest of the unit.
+func (g *ResourceGraph) link(resource *Resource) {
+	namespace := resource.Object.GetNamespace()
+	if service, ok := resource.Object.(*v1.Service); ok && hasSelector(service) {
+		g.addEdges(EdgeSelects, resource, "", g.SelectWorkloads(namespace, labels.SelectorFromSet(service.Spec.Selector)))
+	}
+	if backends, secretNames, ok := ingressReferences(resource.Object); ok {
+		for _, backend := range backends {
+			g.addEdges(EdgeRoutesTo, resource, backend.field, g.Lookup("Service", namespace, backend.ServiceName))
+		}
+		for _, secretName := range secretNames {
+			g.addEdges(EdgeUsesTLSSecret, resource, "", g.Lookup("Secret", namespace, secretName))
+		}
+	}
+	if binding, ok := asRoleBinding(resource.Object); ok {
+		roleNamespace := ""
+		if binding.roleRef.Kind == "Role" {
+			roleNamespace = binding.namespace
+		}
+		g.addEdges(EdgeBindsRole, resource, "roleRef", g.Lookup(binding.roleRef.Kind, roleNamespace, binding.roleRef.Name))
+		for i, subject := range binding.subjects {
+			if subject.Kind != "ServiceAccount" {
+				continue
+			}
+			subjectNamespace := subject.Namespace
+			if subjectNamespace == "" {
+				subjectNamespace = binding.namespace
+			}
+			g.addEdges(EdgeBindsSubject, resource, fmt.Sprintf("subjects[%d]", i), g.Lookup("ServiceAccount", subjectNamespace, subject.Name))
+		}
+	}
+	if _, podSpec, ok := podTemplate(resource.Object); ok {
+		serviceAccountName := podSpec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = podSpec.DeprecatedServiceAccount
+		}
+		if serviceAccountName != "" {
+			g.addEdges(EdgeRunsAs, resource, "serviceAccountName", g.Lookup("ServiceAccount", namespace, serviceAccountName))
+		}
+		for _, reference := range podReferences(podSpec) {
+			field := reference.field
+			if reference.container != "" {
+				field = fmt.Sprintf("containers[%s].%s", reference.container, reference.field)
+			}
+			g.addEdges(EdgeReferences, resource, field, g.Lookup(reference.kind, namespace, reference.name))
+		}
+	}
+	if target, ok := scaleTarget(resource.Object); ok {
+		g.addEdges(EdgeScales, resource, "scaleTargetRef", g.Lookup(target.Kind, namespace, target.Name))
+	}
+	if budget, ok := resource.Object.(*policyV1beta1.PodDisruptionBudget); ok && budget.Spec.Selector != nil {
+		if selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector); err == nil {
+			g.addEdges(EdgeProtects, resource, "selector", g.SelectWorkloads(namespace, selector))
+		}
+	}
+	if policy, ok := asNetworkPolicy(resource.Object); ok {
+		g.addEdges(EdgeIsolates, resource, "podSelector", g.SelectWorkloads(namespace, policy.selector))
+	}
+}
+
+// scaleTarget gives you the scaleTargetRef of a HorizontalPodAutoscaler of any API version.
+func scaleTarget(object metav1.Object) (autoscalingV1.CrossVersionObjectReference, bool) {
+	switch autoscaler := object.(type) {
+	case *autoscalingV1.HorizontalPodAutoscaler:
+		return autoscaler.Spec.ScaleTargetRef, true
+	case *autoscalingV2beta1.HorizontalPodAutoscaler:
+		return autoscalingV1.CrossVersionObjectReference(autoscaler.Spec.ScaleTargetRef), true
+	case *autoscalingV2beta2.HorizontalPodAutoscaler:
+		return autoscalingV1.CrossVersionObjectReference(autoscaler.Spec.ScaleTargetRef), true
+	}
+	return autoscalingV1.CrossVersionObjectReference{}, false
+}
+
+// WriteDOT writes the graph in the DOT language, so you can draw it with Graphviz, eg
+//
+//	graph.WriteDOT(os.Stdout) // then: dot -Tsvg -o unit.svg
+func (g *ResourceGraph) WriteDOT(w io.Writer) error {
+	ids := make(map[*Resource]string)
+	var b strings.Builder
+	b.WriteString("digraph unit {\n\tnode [shape=box];\n")
+	for i, resource := range g.resources {
+		ids[resource] = fmt.Sprintf("n%d", i)
+		name := resource.Object.GetName()
+		if namespace := resource.Object.GetNamespace(); namespace != "" {
+			name = namespace + "/" + name
+		}
+		fmt.Fprintf(&b, "\t%s [label=%q];\n", ids[resource], resource.TypeInfo.GetKind()+"\n"+name)
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%q];\n", ids[edge.From], ids[edge.To], string(edge.Type))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,116 @@
+package kubelint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleError is reported when a rule's Condition or Fix panics, or its Condition takes longer than the rule timeout.
+// It names the rule and the resources it was being applied to, so you can track down the buggy rule.
+type RuleError struct {
+	RuleID    RuleID                 // the rule that went wrong
+	Resources []*YamlDerivedResource // the resources the rule was being applied to
+	Err       error                  // what went wrong
+}
+
+func (e *RuleError) Error() string {
+	if len(e.Resources) == 0 {
+		return fmt.Sprintf("Rule %s failed: %s", e.RuleID, e.Err)
+	}
+	var resources []string
+	for _, resource := range e.Resources {
+		resources = append(resources, describeResource(resource))
+	}
+	return fmt.Sprintf("Rule %s failed on %s: %s", e.RuleID, strings.Join(resources, ", "), e.Err)
+}
+
+// Unwrap returns the underlying error, eg context.DeadlineExceeded.
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// describeResource gives a short, human readable location for a resource, eg "Deployment hello-world (deployment.yaml:1)"
+func describeResource(ydr *YamlDerivedResource) string {
+	return fmt.Sprintf("%s %s (%s:%d)", ydr.Resource.TypeInfo.GetKind(), ydr.Resource.Object.GetName(), ydr.Filepath, ydr.LineNumber)
+}
+
+// callRecovered calls f, turning a panic into an error.
+func callRecovered(f func() bool) (ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f(), nil
+}
+
+// guardedOutcome carries the return values of callRecovered across a channel.
+type guardedOutcome struct {
+	ok  bool
+	err error
+}
+
+// evaluate calls a rule's Condition, recovering from any panic and giving up once the timeout
+// passes (if there is one) or the context is done. A Condition that times out can't be stopped,
+// so it keeps running in the background, but its answer is ignored. When evaluate gives up on a Condition,
+// it also returns a channel that's closed once the Condition does return, so the caller knows
+// when it's safe to change the resources the Condition is reading.
+func evaluate(ctx context.Context, timeout time.Duration, condition func() bool) (bool, <-chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+	if timeout <= 0 && ctx.Done() == nil {
+		ok, err := callRecovered(condition)
+		return ok, nil, err
+	}
+	done := make(chan guardedOutcome, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ok, err := callRecovered(condition)
+		done <- guardedOutcome{ok: ok, err: err}
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case outcome := <-done:
+		return outcome.ok, nil, outcome.err
+	case <-expired:
+		return false, finished, fmt.Errorf("timed out after %s", timeout)
+	case <-ctx.Done():
+		return false, finished, ctx.Err()
+	}
+}
+
+// abandonedCondition is a rule's Condition that evaluate gave up on, which may still be running.
+type abandonedCondition struct {
+	rule     *rule
+	resource *YamlDerivedResource // the resource it's reading, or nil for an interdependent rule that could be reading any of them
+	finished <-chan struct{}      // closed once the Condition returns
+}
+
+// running tells whether the Condition still hasn't returned.
+func (c *abandonedCondition) running() bool {
+	select {
+	case <-c.finished:
+		return false
+	default:
+		return true
+	}
+}
+
+// stillRunningError reports that fixes were held back because a rule's Condition timed out and hasn't returned yet.
+func stillRunningError(condition *abandonedCondition) error {
+	var resources []*YamlDerivedResource
+	if condition.resource != nil {
+		resources = []*YamlDerivedResource{condition.resource}
+	}
+	return &RuleError{RuleID: condition.rule.ID, Resources: resources, Err: fmt.Errorf("its Condition timed out and is still running, so fixes that could change what it's reading weren't applied")}
+}
@@ -0,0 +1,163 @@
+package kubelint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// The default registry and the namespace its official images live in, for references like "nginx".
+const (
+	defaultRegistry         = "docker.io"
+	officialImagesNamespace = "library"
+)
+
+// imageDigestsDecodeBuffer is how far into an image digests file to look to tell if it's JSON or YAML.
+const imageDigestsDecodeBuffer = 4096
+
+// The grammar of an image reference, from https://github.com/distribution/distribution/blob/main/reference/reference.go
+var (
+	imagePathComponentPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+	imageRegistryPattern      = regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?$`)
+	imageTagPattern           = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestPattern        = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+// ImageReference is a container image reference split into its parts,
+// eg quay.io/coreos/etcd:v3.4 is the repository coreos/etcd in the registry quay.io with the tag v3.4.
+type ImageReference struct {
+	Registry   string // eg quay.io, docker.io when the reference doesn't name one
+	Repository string // eg coreos/etcd, library/nginx for official images on docker.io
+	Tag        string // eg v3.4, empty when the reference doesn't have one
+	Digest     string // eg sha256:0123..., empty when the image isn't pinned to one
+}
+
+// ParseImageReference splits an image reference (the image field of a container) into its parts.
+func ParseImageReference(image string) (*ImageReference, error) {
+	reference := &ImageReference{}
+	name := image
+	if at := strings.IndexByte(name, '@'); at != -1 {
+		name, reference.Digest = name[:at], name[at+1:]
+		if !imageDigestPattern.MatchString(reference.Digest) {
+			return nil, fmt.Errorf("Image %q has an invalid digest %q", image, reference.Digest)
+		}
+	}
+	if colon := strings.LastIndexByte(name, ':'); colon > strings.LastIndexByte(name, '/') {
+		name, reference.Tag = name[:colon], name[colon+1:]
+		if !imageTagPattern.MatchString(reference.Tag) {
+			return nil, fmt.Errorf("Image %q has an invalid tag %q", image, reference.Tag)
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("Image %q doesn't name a repository", image)
+	}
+	reference.Registry, reference.Repository = defaultRegistry, name
+	if slash := strings.IndexByte(name, '/'); slash != -1 {
+		if host := name[:slash]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			if !imageRegistryPattern.MatchString(host) {
+				return nil, fmt.Errorf("Image %q has an invalid registry %q", image, host)
+			}
+			reference.Registry, reference.Repository = host, name[slash+1:]
+		}
+	}
+	for _, component := range strings.Split(reference.Repository, "/") {
+		if !imagePathComponentPattern.MatchString(component) {
+			return nil, fmt.Errorf("Image %q has an invalid repository %q", image, reference.Repository)
+		}
+	}
+	if reference.Registry == defaultRegistry && !strings.Contains(reference.Repository, "/") {
+		reference.Repository = officialImagesNamespace + "/" + reference.Repository
+	}
+	return reference, nil
+}
+
+// Name is the registry and repository, eg docker.io/library/nginx.
+func (r *ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String is the full reference, eg docker.io/library/nginx:1.17.
+func (r *ImageReference) String() string {
+	reference := r.Name()
+	if r.Tag != "" {
+		reference += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		reference += "@" + r.Digest
+	}
+	return reference
+}
+
+// IsMutable tells you if the image the reference points to can change without the reference changing,
+// which is true unless it's pinned to a digest.
+func (r *ImageReference) IsMutable() bool {
+	return r.Digest == ""
+}
+
+// IsLatest tells you if the reference uses the latest tag, or no tag at all (which means latest), without a digest.
+func (r *ImageReference) IsLatest() bool {
+	return r.Digest == "" && (r.Tag == "" || r.Tag == "latest")
+}
+
+// ImageDigests maps images to the digests they should be pinned to. It's keyed by the image's full name and tag
+// (eg docker.io/library/nginx:1.17), so make one with ReadImageDigests rather than by hand.
+type ImageDigests map[string]string
+
+// ReadImageDigests reads a YAML or JSON file mapping image references to digests, eg
+//
+//	nginx:latest: sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac
+//	quay.io/coreos/etcd:v3.4.3: sha256:f2c6bde0c2d1bf5e4dbd6f5d1a0e1f30fdc4ab1fd7a1e78d2bb5b68e2b2a8ce0
+//
+// The references are parsed, so nginx, nginx:latest and docker.io/library/nginx:latest are all the same image.
+func ReadImageDigests(filepath string) (ImageDigests, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var mapping map[string]string
+	if err := yaml.NewYAMLOrJSONDecoder(f, imageDigestsDecodeBuffer).Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("Couldn't read the image digests in %s: %s", filepath, err)
+	}
+	digests := make(ImageDigests)
+	for image, digest := range mapping {
+		reference, err := ParseImageReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filepath, err)
+		}
+		if !imageDigestPattern.MatchString(digest) {
+			return nil, fmt.Errorf("%s: image %q has an invalid digest %q", filepath, image, digest)
+		}
+		digests[imageDigestKey(reference)] = digest
+	}
+	return digests, nil
+}
+
+// imageDigestKey is what an image is looked up by in ImageDigests, its name and tag (latest if it doesn't have one).
+func imageDigestKey(reference *ImageReference) string {
+	tag := reference.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	return reference.Name() + ":" + tag
+}
+
+// digestFor finds the digest an image should be pinned to, and false if it isn't in the mapping.
+func (d ImageDigests) digestFor(reference *ImageReference) (string, bool) {
+	digest, found := d[imageDigestKey(reference)]
+	return digest, found
+}
+
+// pinImage rewrites the image to use the digest instead of its tag, keeping the registry and repository the way they were written.
+func pinImage(image string, reference *ImageReference, digest string) string {
+	if at := strings.IndexByte(image, '@'); at != -1 {
+		image = image[:at]
+	}
+	if reference.Tag != "" {
+		image = strings.TrimSuffix(image, ":"+reference.Tag)
+	}
+	return image + "@" + digest
+}
@@ -0,0 +1,104 @@
+package kubelint
+
+import (
+	"encoding/json"
+	"strings"
+
+	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The annotation that picks the ingress controller for an Ingress. The ingressClassName field
+// that replaces it isn't in the API version kubelint is built against.
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// convertExtensionsIngress gives you an extensions/v1beta1 Ingress as the equivalent networking.k8s.io/v1beta1 Ingress.
+// The original is left alone.
+func convertExtensionsIngress(ingress *v1beta1Extensions.Ingress) (*networkingV1beta1.Ingress, error) {
+	converted := &networkingV1beta1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingV1beta1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
+		ObjectMeta: *ingress.ObjectMeta.DeepCopy(),
+	}
+	// the spec and status have the same fields in both API versions, just in different packages
+	spec, err := json.Marshal(ingress.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(spec, &converted.Spec); err != nil {
+		return nil, err
+	}
+	status, err := json.Marshal(ingress.Status)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(status, &converted.Status); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// ingressSpec gives you the spec of an extensions/v1beta1 or networking.k8s.io/v1beta1 Ingress, in the newer API's shape.
+// The last return value is false if the object isn't an ingress.
+func ingressSpec(object metav1.Object) (*networkingV1beta1.IngressSpec, bool) {
+	switch ingress := object.(type) {
+	case *networkingV1beta1.Ingress:
+		return &ingress.Spec, true
+	case *v1beta1Extensions.Ingress:
+		converted, err := convertExtensionsIngress(ingress)
+		if err != nil {
+			return nil, false
+		}
+		return &converted.Spec, true
+	}
+	return nil, false
+}
+
+// tlsCoversHosts tells you if the ingress serves every host in its rules over TLS, or has a TLS section if it has no hosts.
+func tlsCoversHosts(spec *networkingV1beta1.IngressSpec) bool {
+	if len(spec.TLS) == 0 {
+		return false
+	}
+	for _, rule := range spec.Rules {
+		if rule.Host != "" && !tlsCoversHost(spec.TLS, rule.Host) {
+			return false
+		}
+	}
+	return true
+}
+
+// tlsCoversHost tells you if one of the TLS sections lists the host, or a wildcard that matches it, eg *.example.com for a.example.com
+func tlsCoversHost(tls []networkingV1beta1.IngressTLS, host string) bool {
+	for _, section := range tls {
+		for _, tlsHost := range section.Hosts {
+			if tlsHost == host {
+				return true
+			}
+			if strings.HasPrefix(tlsHost, "*.") && !strings.HasPrefix(host, "*.") {
+				// a wildcard only covers one label, so *.example.com doesn't cover a.b.example.com
+				if i := strings.Index(host, "."); i != -1 && host[i:] == tlsHost[1:] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasWildcardHost tells you if any of the ingress's rules is for a wildcard host, eg *.example.com
+func hasWildcardHost(spec *networkingV1beta1.IngressSpec) bool {
+	for _, rule := range spec.Rules {
+		if strings.HasPrefix(rule.Host, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIngressClass tells you if the ingress says which ingress controller should serve it.
+func hasIngressClass(object metav1.Object) bool {
+	return strings.TrimSpace(object.GetAnnotations()[ingressClassAnnotation]) != ""
+}
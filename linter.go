@@ -1,18 +1,25 @@
 package kubelint
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
 	networkingV1 "k8s.io/api/networking/v1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
 	rbacV1 "k8s.io/api/rbac/v1"
 	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
 
 	"os"
 )
@@ -20,28 +27,41 @@ import (
 // Linter: This Linter represents something you can pass in resources to
 // and get results out of that you can eventually log.
 // Also some utility methods for input handling.
+// The linter only stores rules; everything found while linting belongs to a Session.
 type Linter struct {
 	logger                              *log.Logger
-	appsV1DeploymentRules               []*AppsV1DeploymentRule               // a register for all user-defined appsV1Deployment rules
-	v1NamespaceRules                    []*V1NamespaceRule                    // a register for all user-defined v1Namespace rules
-	v1PodSpecRules                      []*V1PodSpecRule                      // a register for all user-defined v1PodSpec rules
-	v1ContainerRules                    []*V1ContainerRule                    // a register for all user-defined v1Container rules
-	v1PersistentVolumeClaimRules        []*V1PersistentVolumeClaimRule        // a register for all user-defined v1PersistentVolumeClaim rules
-	v1Beta1ExtensionsDeploymentRules    []*V1Beta1ExtensionsDeploymentRule    // a register for all user-defined v1Beta1ExtensionsDeployment rules
-	batchV1JobRules                     []*BatchV1JobRule                     // a register for all user-defined batchV1Job rules
-	batchV1Beta1CronJobRules            []*BatchV1Beta1CronJobRule            // a register for all user-defined batchV1Beta1CronJob rules
-	v1Beta1ExtensionsIngressRules       []*V1Beta1ExtensionsIngressRule       // a register for all user-defined v1Beta1ExtensionsIngress rules
-	networkingV1NetworkPolicyRules      []*NetworkingV1NetworkPolicyRule      // a register for all user-defined networkingV1NetworkPolicy rules
-	v1Beta1ExtensionsNetworkPolicyRules []*V1Beta1ExtensionsNetworkPolicyRule // a register for all user-defined v1Beta1ExtensionsNetworkPolicy rules
-	rbacV1RoleRules                     []*RbacV1RoleRule                     // a register for all user-defined rbacV1Role rules
-	rbacV1Beta1RoleBindingRules         []*RbacV1Beta1RoleBindingRule         // a register for all user-defined rbacV1Beta1RoleBinding rules
-	v1ServiceAccountRules               []*V1ServiceAccountRule               // a register for all user-defined v1ServiceAccount rules
-	v1ServiceRules                      []*V1ServiceRule                      // a register for all user-defined v1Service rules
-	genericRules                        []*GenericRule                        // a register for all user-defined Generic rules (applied to every object)
-	interdependentRules                 []*InterdependentRule                 // a register for all user-defined Interdependent rules (applied to the system as a whole)
-	fixes                               []*ruleSorter                         // fixes that should be applied to the resources in order to mitigate some errors on a future pass
-	interdependentFixes                 []*interdependentRule
-	resources                           []*Resource // All the resources that have been read in by this linter
+	appsV1DeploymentRules               []*AppsV1DeploymentRule                          // a register for all user-defined appsV1Deployment rules
+	v1NamespaceRules                    []*V1NamespaceRule                               // a register for all user-defined v1Namespace rules
+	v1PodSpecRules                      []*V1PodSpecRule                                 // a register for all user-defined v1PodSpec rules
+	v1ContainerRules                    []*V1ContainerRule                               // a register for all user-defined v1Container rules
+	workloadV1PodSpecRules              []*V1PodSpecRule                                 // a register for the v1PodSpec rules that are applied to the pods of every workload
+	workloadV1ContainerRules            []*V1ContainerRule                               // a register for the v1Container rules that are applied to every container (init containers included) of every workload
+	v1PersistentVolumeClaimRules        []*V1PersistentVolumeClaimRule                   // a register for all user-defined v1PersistentVolumeClaim rules
+	v1Beta1ExtensionsDeploymentRules    []*V1Beta1ExtensionsDeploymentRule               // a register for all user-defined v1Beta1ExtensionsDeployment rules
+	batchV1JobRules                     []*BatchV1JobRule                                // a register for all user-defined batchV1Job rules
+	batchV1Beta1CronJobRules            []*BatchV1Beta1CronJobRule                       // a register for all user-defined batchV1Beta1CronJob rules
+	v1Beta1ExtensionsIngressRules       []*V1Beta1ExtensionsIngressRule                  // a register for all user-defined v1Beta1ExtensionsIngress rules
+	networkingV1beta1IngressRules       []*NetworkingV1beta1IngressRule                  // a register for all user-defined networkingV1beta1Ingress rules
+	networkingV1NetworkPolicyRules      []*NetworkingV1NetworkPolicyRule                 // a register for all user-defined networkingV1NetworkPolicy rules
+	v1Beta1ExtensionsNetworkPolicyRules []*V1Beta1ExtensionsNetworkPolicyRule            // a register for all user-defined v1Beta1ExtensionsNetworkPolicy rules
+	rbacV1RoleRules                     []*RbacV1RoleRule                                // a register for all user-defined rbacV1Role rules
+	rbacV1Beta1RoleBindingRules         []*RbacV1Beta1RoleBindingRule                    // a register for all user-defined rbacV1Beta1RoleBinding rules
+	rbacV1RoleBindingRules              []*RbacV1RoleBindingRule                         // a register for all user-defined rbacV1RoleBinding rules
+	rbacV1ClusterRoleRules              []*RbacV1ClusterRoleRule                         // a register for all user-defined rbacV1ClusterRole rules
+	rbacV1ClusterRoleBindingRules       []*RbacV1ClusterRoleBindingRule                  // a register for all user-defined rbacV1ClusterRoleBinding rules
+	v1ServiceAccountRules               []*V1ServiceAccountRule                          // a register for all user-defined v1ServiceAccount rules
+	v1ServiceRules                      []*V1ServiceRule                                 // a register for all user-defined v1Service rules
+	v1ConfigMapRules                    []*V1ConfigMapRule                               // a register for all user-defined v1ConfigMap rules
+	v1SecretRules                       []*V1SecretRule                                  // a register for all user-defined v1Secret rules
+	autoscalingV1HPARules               []*AutoscalingV1HorizontalPodAutoscalerRule      // a register for all user-defined autoscalingV1HorizontalPodAutoscaler rules
+	autoscalingV2beta2HPARules          []*AutoscalingV2beta2HorizontalPodAutoscalerRule // a register for all user-defined autoscalingV2beta2HorizontalPodAutoscaler rules
+	policyV1beta1PDBRules               []*PolicyV1beta1PodDisruptionBudgetRule          // a register for all user-defined policyV1beta1PodDisruptionBudget rules
+	genericRules                        []*GenericRule                                   // a register for all user-defined Generic rules (applied to every object)
+	interdependentRules                 []*InterdependentRule                            // a register for all user-defined Interdependent rules (applied to the system as a whole)
+	controls                            map[RuleID]string                                // the Pod Security Standards control checked by each rule added through a Profile
+	workers                             int                                              // how many resources can be linted at the same time
+	ruleTimeout                         time.Duration                                    // how long a rule's Condition can run for before giving up on it
+	restMapper                          meta.RESTMapper                                  // tells which kinds are namespaced, ahead of the built-in table
 }
 
 //	NewDefaultLinter returns a linter with absolutely no rules.
@@ -59,169 +79,101 @@ func NewLinter(l *log.Logger) *Linter {
 	return &Linter{logger: l}
 }
 
+// SetWorkers sets how many resources are linted at the same time. By default, resources are linted one after the other.
+// Only use more than one worker if the Condition and Fix functions of your rules are safe to run concurrently, ie
+// they only look at the object they're given. The order of the results doesn't depend on the number of workers.
+func (l *Linter) SetWorkers(workers int) {
+	l.workers = workers
+}
+
+// SetRuleTimeout limits how long a single rule's Condition can take. A rule that takes longer is reported as a RuleError
+// and treated as if it had panicked. The Condition can't be stopped, so it will carry on in the background until it returns.
+// Until it does, a session won't apply any fix that could change what it's reading (the fixes for its resource and the
+// interdependent fixes, or every fix if it's an interdependent rule) and reports a RuleError instead. The Condition still
+// reads its resource while you're using it though, so don't change the resources yourself until it's done.
+// By default there is no limit.
+func (l *Linter) SetRuleTimeout(timeout time.Duration) {
+	l.ruleTimeout = timeout
+}
+
+// SetRESTMapper gives the linter a RESTMapper to find out whether a kind is namespaced or cluster scoped, eg one built
+// from an API server's discovery information, or a meta.DefaultRESTMapper listing your custom resources. Kinds the mapper
+// doesn't know about fall back to the built-in table of cluster scoped kinds, which only has the kinds built into Kubernetes,
+// so without a mapper every custom resource is treated as namespaced (and INTERDEPENDENT_MATCHING_NAMESPACE will try to move it).
+func (l *Linter) SetRESTMapper(mapper meta.RESTMapper) {
+	l.restMapper = mapper
+}
+
+// NewSession starts a new run of the linter. The session keeps track of the resources it lints,
+// the results and any fixes waiting to be applied, so the linter itself can be reused (even concurrently)
+// as long as no rules are added to it while a session is using it. Each session should only be used by one goroutine at a time.
+func (l *Linter) NewSession() *Session {
+	return &Session{linter: l, logger: l.logger}
+}
+
 // Lint opens and lints the files and produces results that
-// can be logged later on
+// can be logged later on. Every call runs in a session of its own that's thrown away afterwards,
+// so it's safe to call from several goroutines at once. To fix the resources, lint them in a session
+// from NewSession and call its ApplyFixes.
 func (l *Linter) Lint(filepaths ...string) ([]*Result, []error) {
-	l.logger.Debugf("Linting files: %#v\n", filepaths)
-	var errors []error
-	resources, errs := Read(filepaths...)
-	for _, resource := range resources {
-		l.resources = append(l.resources, &resource.Resource)
-	}
-	errors = append(errors, errs...)
-	var results []*Result
-	// add interdependent checks
-	results = append(results, l.lintResources(resources)...)
-	for _, resource := range resources {
-		r, err := l.LintResource(resource)
-		l.logger.Debugln("results from linting", resource.Filepath, r)
-		results = append(results, r...)
-		if err != nil {
-			l.logger.Debugln("Error from LintResource: ", err)
-			errors = append(errors, err)
-		}
-	}
-	return results, errors
+	return l.LintContext(context.Background(), filepaths...)
+}
+
+// LintContext is like Lint, but stops linting as soon as the context is done.
+func (l *Linter) LintContext(ctx context.Context, filepaths ...string) ([]*Result, []error) {
+	return l.NewSession().LintContext(ctx, filepaths...)
 }
 
 //	LintBytes takes a slice of bytes to lint and a filepath and
-//	returns a list of Results and errors to report or log later on
+//	returns a list of Results and errors to report or log later on. Like Lint, every call runs in a session of its own.
 func (l *Linter) LintBytes(data []byte, filepath string) ([]*Result, []error) {
-	resources, errors := ReadBytes(data, filepath)
-	for _, resource := range resources {
-		l.resources = append(l.resources, &resource.Resource)
-	}
-	var results []*Result
-	// add interdependent checks
-	results = append(results, l.lintResources(resources)...)
-	for _, resource := range resources {
-		r, err := l.LintResource(resource)
-		results = append(results, r...)
-		if err != nil {
-			errors = append(errors, err)
-		}
-	}
-	return results, errors
+	return l.LintBytesContext(context.Background(), data, filepath)
+}
+
+// LintBytesContext is like LintBytes, but stops linting as soon as the context is done.
+func (l *Linter) LintBytesContext(ctx context.Context, data []byte, filepath string) ([]*Result, []error) {
+	return l.NewSession().LintBytesContext(ctx, data, filepath)
 }
 
 //	LintFile takes a file pointer and returns a list of Reults and Errors
-//	to be logged or reported later on
+//	to be logged or reported later on. Like Lint, every call runs in a session of its own.
 func (l *Linter) LintFile(file *os.File) ([]*Result, []error) {
-	resources, errors := ReadFile(file)
-	for _, resource := range resources {
-		l.resources = append(l.resources, &resource.Resource)
-	}
-	var results []*Result
-	// add interdependent checks
-	results = append(results, l.lintResources(resources)...)
-	for _, resource := range resources {
-		r, err := l.LintResource(resource)
-		results = append(results, r...)
-		if err != nil {
-			errors = append(errors, err)
-		}
-	}
-	return results, errors
+	return l.LintFileContext(context.Background(), file)
 }
 
-//	lintResources takes a list of Yaml Derived Resources, applying interdependent rules ONLY
-//   and returns a list of Results
-//	to be logged or reported
-func (l *Linter) lintResources(resources []*YamlDerivedResource) []*Result {
-	var results []*Result
-	rules := l.createInterdependentRules(resources)
-	for _, rule := range rules {
-		if !rule.Condition() {
-			results = append(results, &Result{
-				Resources: rule.Resources,
-				Message:   rule.Message,
-				Level:     rule.Level,
-			})
-			l.interdependentFixes = append(l.interdependentFixes, rule)
-		}
-	}
-	return results
-}
-
-// LintResource takes a yaml derived resource and returns a list of results and errors
-// to be logged or reported
-func (l *Linter) LintResource(resource *YamlDerivedResource) ([]*Result, error) {
-	var results []*Result
-	rules, err := l.createRules(resource)
-	l.logger.Debugln(len(rules), "rules created for", resource.Filepath)
-	// log rules and their dependent rules
-	for _, rule := range rules {
-		l.logger.Debugf("Rule ID: %s\n\tPrereqs: %#v\n", rule.ID, rule.Prereqs)
-	}
-	ruleSorter := newRuleSorter(rules)
-	fixSorter := ruleSorter.clone()
-	l.fixes = append(l.fixes, fixSorter)
-	for !ruleSorter.isEmpty() {
-		rule := ruleSorter.popNextAvailable()
-		l.logger.Debugln("Testing rule", rule.ID)
-		if !rule.Condition() {
-			l.logger.Debugln("Rule failed")
-			results = append(results, &Result{
-				Resources: []*YamlDerivedResource{resource},
-				Message:   rule.Message,
-				Level:     rule.Level,
-			})
-			l.logger.Debugf("Adding result: %#v\n", results[len(results)-1])
-			dependentRules := ruleSorter.popDependentRules(rule.ID)
-			l.logger.Debugf("Dependent rules:\n")
-			for _, rule := range dependentRules {
-				l.logger.Debugln(rule.ID)
-			}
-			for _, dependentRule := range dependentRules {
-				results = append(results, &Result{
-					Resources: []*YamlDerivedResource{resource},
-					Message:   dependentRule.Message,
-					Level:     dependentRule.Level,
-				})
-			}
-		} else {
-			// this doesn't need to be fixed, so remove it from the fixSorter
-			fixSorter.remove(rule.ID)
-		}
-	}
-	return results, err
-}
-
-//	ApplyFixes applies all fixes that were registered as necessary during the lint phase.
-//	The references to all the objects are kept in the Resources array so it will be reflected there.
-func (l *Linter) ApplyFixes() ([]*Resource, []string) {
-	var appliedFixDescriptions []string
-	for _, sorter := range l.fixes {
-		for !sorter.isEmpty() {
-			rule := sorter.popNextAvailable()
-			fixed := rule.Fix()
-			if !fixed {
-				_ = sorter.popDependentRules(rule.ID)
-			} else {
-				appliedFixDescriptions = append(appliedFixDescriptions, rule.FixDescription())
-			}
-		}
-	}
-	for _, rule := range l.interdependentFixes {
-		fixed := rule.Fix()
-		if fixed {
-			appliedFixDescriptions = append(appliedFixDescriptions, rule.FixDescription())
-		}
-	}
-
-	return l.resources, appliedFixDescriptions
+// LintFileContext is like LintFile, but stops linting as soon as the context is done.
+func (l *Linter) LintFileContext(ctx context.Context, file *os.File) ([]*Result, []error) {
+	return l.NewSession().LintFileContext(ctx, file)
 }
 
 //	createInterdependentRules finds the registered interdependent rules and transforms them
 //	to generic rules by applying the ydrs parameter.
-func (l *Linter) createInterdependentRules(ydrs []*YamlDerivedResource) []*interdependentRule {
-	var rules []*interdependentRule
+func (l *Linter) createInterdependentRules(ydrs []*YamlDerivedResource) []*rule {
+	var rules []*rule
+	graph := l.resourceGraph(ydrs)
 	for _, interdependentRule := range l.interdependentRules {
-		rules = append(rules, interdependentRule.createRule(ydrs))
+		rules = append(rules, interdependentRule.createRule(ydrs, graph))
 	}
 	return rules
 }
 
+// resourceGraph builds the graph of the unit for the interdependent rules that need it,
+// or returns nil if none of them do, since there's no point paying for it.
+func (l *Linter) resourceGraph(ydrs []*YamlDerivedResource) *ResourceGraph {
+	for _, interdependentRule := range l.interdependentRules {
+		if interdependentRule.GraphCondition != nil || interdependentRule.Violations != nil {
+			var resources []*Resource
+			for _, ydr := range ydrs {
+				resources = append(resources, &ydr.Resource)
+			}
+			graph := NewResourceGraph(resources)
+			graph.restMapper = l.restMapper
+			return graph
+		}
+	}
+	return nil
+}
+
 // createRules finds the type-appropriate rules that are registered in the linter
 // and transforms them to generic rules by applying the resource parameter.
 // Then the list of rules are returned. I think I put it into a ruleSorter later on.
@@ -243,7 +195,7 @@ func (l *Linter) createRules(ydr *YamlDerivedResource) ([]*rule, error) {
 			rules = append(rules, podSpecRule.createRule(&concrete.Spec.Template.Spec, ydr))
 		}
 		for _, v1ContainerRule := range l.v1ContainerRules {
-			for i, _ := range concrete.Spec.Template.Spec.Containers {
+			for i := range concrete.Spec.Template.Spec.Containers {
 				rules = append(rules, v1ContainerRule.createRule(&concrete.Spec.Template.Spec.Containers[i], ydr))
 			}
 		}
@@ -271,6 +223,10 @@ func (l *Linter) createRules(ydr *YamlDerivedResource) ([]*rule, error) {
 		for _, v1Beta1ExtensionsIngressRule := range l.v1Beta1ExtensionsIngressRules {
 			rules = append(rules, v1Beta1ExtensionsIngressRule.createRule(concrete, ydr))
 		}
+	case *networkingV1beta1.Ingress:
+		for _, networkingV1beta1IngressRule := range l.networkingV1beta1IngressRules {
+			rules = append(rules, networkingV1beta1IngressRule.createRule(concrete, ydr))
+		}
 	case *networkingV1.NetworkPolicy:
 		for _, networkingV1NetworkPolicyRule := range l.networkingV1NetworkPolicyRules {
 			rules = append(rules, networkingV1NetworkPolicyRule.createRule(concrete, ydr))
@@ -287,6 +243,18 @@ func (l *Linter) createRules(ydr *YamlDerivedResource) ([]*rule, error) {
 		for _, rbacV1Beta1RoleBindingRule := range l.rbacV1Beta1RoleBindingRules {
 			rules = append(rules, rbacV1Beta1RoleBindingRule.createRule(concrete, ydr))
 		}
+	case *rbacV1.RoleBinding:
+		for _, rbacV1RoleBindingRule := range l.rbacV1RoleBindingRules {
+			rules = append(rules, rbacV1RoleBindingRule.createRule(concrete, ydr))
+		}
+	case *rbacV1.ClusterRole:
+		for _, rbacV1ClusterRoleRule := range l.rbacV1ClusterRoleRules {
+			rules = append(rules, rbacV1ClusterRoleRule.createRule(concrete, ydr))
+		}
+	case *rbacV1.ClusterRoleBinding:
+		for _, rbacV1ClusterRoleBindingRule := range l.rbacV1ClusterRoleBindingRules {
+			rules = append(rules, rbacV1ClusterRoleBindingRule.createRule(concrete, ydr))
+		}
 	case *v1.ServiceAccount:
 		for _, v1ServiceAccountRule := range l.v1ServiceAccountRules {
 			rules = append(rules, v1ServiceAccountRule.createRule(concrete, ydr))
@@ -295,11 +263,93 @@ func (l *Linter) createRules(ydr *YamlDerivedResource) ([]*rule, error) {
 		for _, v1ServiceRule := range l.v1ServiceRules {
 			rules = append(rules, v1ServiceRule.createRule(concrete, ydr))
 		}
+	case *v1.ConfigMap:
+		for _, v1ConfigMapRule := range l.v1ConfigMapRules {
+			rules = append(rules, v1ConfigMapRule.createRule(concrete, ydr))
+		}
+	case *v1.Secret:
+		for _, v1SecretRule := range l.v1SecretRules {
+			rules = append(rules, v1SecretRule.createRule(concrete, ydr))
+		}
+	case *autoscalingV1.HorizontalPodAutoscaler:
+		for _, autoscalingV1HPARule := range l.autoscalingV1HPARules {
+			rules = append(rules, autoscalingV1HPARule.createRule(concrete, ydr))
+		}
+	case *autoscalingV2beta2.HorizontalPodAutoscaler:
+		for _, autoscalingV2beta2HPARule := range l.autoscalingV2beta2HPARules {
+			rules = append(rules, autoscalingV2beta2HPARule.createRule(concrete, ydr))
+		}
+	case *policyV1beta1.PodDisruptionBudget:
+		for _, policyV1beta1PDBRule := range l.policyV1beta1PDBRules {
+			rules = append(rules, policyV1beta1PDBRule.createRule(concrete, ydr))
+		}
 
 	default:
-		return nil, fmt.Errorf("Resources of type %T have not been considered by the linter", concrete)
+		// the only rules for other workloads are the workload rules, so without any they haven't been considered either
+		if _, _, ok := podTemplate(concrete); !ok || !l.hasWorkloadRules() {
+			return nil, fmt.Errorf("Resources of type %T have not been considered by the linter", concrete)
+		}
+	}
+	// the workload rules have every workload's pods checked, not just Deployments
+	if _, podSpec, ok := podTemplate(resource.Object); ok {
+		rules = append(rules, l.createWorkloadRules(podSpec, ydr)...)
 	}
-	return rules, nil
+	return l.scopeContainerPrereqs(rules), nil
+}
+
+// hasWorkloadRules tells whether any rules have been registered for the pods of every workload.
+func (l *Linter) hasWorkloadRules() bool {
+	return len(l.workloadV1PodSpecRules) != 0 || len(l.workloadV1ContainerRules) != 0
+}
+
+// createWorkloadRules applies the workload pod spec rules to the pod spec, and the workload container rules
+// to each of its containers (init containers included).
+func (l *Linter) createWorkloadRules(podSpec *v1.PodSpec, ydr *YamlDerivedResource) []*rule {
+	var rules []*rule
+	for _, podSpecRule := range l.workloadV1PodSpecRules {
+		rules = append(rules, podSpecRule.createRule(podSpec, ydr))
+	}
+	for _, v1ContainerRule := range l.workloadV1ContainerRules {
+		for i := range podSpec.InitContainers {
+			rules = append(rules, v1ContainerRule.createRule(&podSpec.InitContainers[i], ydr))
+		}
+		for i := range podSpec.Containers {
+			rules = append(rules, v1ContainerRule.createRule(&podSpec.Containers[i], ydr))
+		}
+	}
+	return rules
+}
+
+// scopeContainerPrereqs points prerequisites on container rules at the rules that were created for each container.
+// A container rule depends on the rules for its own container, any other rule depends on the rules for every container.
+func (l *Linter) scopeContainerPrereqs(rules []*rule) []*rule {
+	containers := make(map[RuleID][]string)
+	for _, v1ContainerRule := range append(append([]*V1ContainerRule{}, l.v1ContainerRules...), l.workloadV1ContainerRules...) {
+		containers[v1ContainerRule.ID] = nil
+	}
+	for _, rule := range rules {
+		if rule.container != "" {
+			containers[rule.ID] = append(containers[rule.ID], rule.container)
+		}
+	}
+	for _, rule := range rules {
+		var prereqs []RuleID
+		for _, prereq := range rule.Prereqs {
+			names, isContainerRule := containers[prereq]
+			switch {
+			case !isContainerRule:
+				prereqs = append(prereqs, prereq)
+			case rule.container != "":
+				prereqs = append(prereqs, containerRuleKey(prereq, rule.container))
+			default:
+				for _, name := range names {
+					prereqs = append(prereqs, containerRuleKey(prereq, name))
+				}
+			}
+		}
+		rule.Prereqs = prereqs
+	}
+	return rules
 }
 
 //	AddAppsV1DeploymentRule adds a custom rule (or many) so that anything sent through the linter of the correct type
@@ -326,6 +376,19 @@ func (l *Linter) AddV1ContainerRule(rules ...*V1ContainerRule) {
 	l.v1ContainerRules = append(l.v1ContainerRules, rules...)
 }
 
+// AddWorkloadV1PodSpecRule adds a custom rule (or many) that is applied to the pods of every workload
+// (Pods, Deployments, StatefulSets, DaemonSets, ReplicaSets, ReplicationControllers, Jobs and CronJobs),
+// rather than just the apps/v1 Deployments AddV1PodSpecRule's rules are applied to.
+func (l *Linter) AddWorkloadV1PodSpecRule(rules ...*V1PodSpecRule) {
+	l.workloadV1PodSpecRules = append(l.workloadV1PodSpecRules, rules...)
+}
+
+// AddWorkloadV1ContainerRule adds a custom rule (or many) that is applied to every container of every workload,
+// init containers included, rather than just the containers of the apps/v1 Deployments AddV1ContainerRule's rules are applied to.
+func (l *Linter) AddWorkloadV1ContainerRule(rules ...*V1ContainerRule) {
+	l.workloadV1ContainerRules = append(l.workloadV1ContainerRules, rules...)
+}
+
 //	AddV1PersistentVolumeClaimRule adds a custom rule (or many) so that anything sent through the linter of the correct type
 //	has this rule applied to it.
 func (l *Linter) AddV1PersistentVolumeClaimRule(rules ...*V1PersistentVolumeClaimRule) {
@@ -356,6 +419,12 @@ func (l *Linter) AddV1Beta1ExtensionsIngressRule(rules ...*V1Beta1ExtensionsIngr
 	l.v1Beta1ExtensionsIngressRules = append(l.v1Beta1ExtensionsIngressRules, rules...)
 }
 
+//	AddNetworkingV1beta1IngressRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddNetworkingV1beta1IngressRule(rules ...*NetworkingV1beta1IngressRule) {
+	l.networkingV1beta1IngressRules = append(l.networkingV1beta1IngressRules, rules...)
+}
+
 //	AddNetworkingV1NetworkPolicyRule adds a custom rule (or many) so that anything sent through the linter of the correct type
 //	has this rule applied to it.
 func (l *Linter) AddNetworkingV1NetworkPolicyRule(rules ...*NetworkingV1NetworkPolicyRule) {
@@ -380,6 +449,24 @@ func (l *Linter) AddRbacV1Beta1RoleBindingRule(rules ...*RbacV1Beta1RoleBindingR
 	l.rbacV1Beta1RoleBindingRules = append(l.rbacV1Beta1RoleBindingRules, rules...)
 }
 
+//	AddRbacV1RoleBindingRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddRbacV1RoleBindingRule(rules ...*RbacV1RoleBindingRule) {
+	l.rbacV1RoleBindingRules = append(l.rbacV1RoleBindingRules, rules...)
+}
+
+//	AddRbacV1ClusterRoleRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddRbacV1ClusterRoleRule(rules ...*RbacV1ClusterRoleRule) {
+	l.rbacV1ClusterRoleRules = append(l.rbacV1ClusterRoleRules, rules...)
+}
+
+//	AddRbacV1ClusterRoleBindingRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddRbacV1ClusterRoleBindingRule(rules ...*RbacV1ClusterRoleBindingRule) {
+	l.rbacV1ClusterRoleBindingRules = append(l.rbacV1ClusterRoleBindingRules, rules...)
+}
+
 //	AddV1ServiceAccountRule adds a custom rule (or many) so that anything sent through the linter of the correct type
 //	has this rule applied to it.
 func (l *Linter) AddV1ServiceAccountRule(rules ...*V1ServiceAccountRule) {
@@ -392,6 +479,36 @@ func (l *Linter) AddV1ServiceRule(rules ...*V1ServiceRule) {
 	l.v1ServiceRules = append(l.v1ServiceRules, rules...)
 }
 
+//	AddV1ConfigMapRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddV1ConfigMapRule(rules ...*V1ConfigMapRule) {
+	l.v1ConfigMapRules = append(l.v1ConfigMapRules, rules...)
+}
+
+//	AddV1SecretRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddV1SecretRule(rules ...*V1SecretRule) {
+	l.v1SecretRules = append(l.v1SecretRules, rules...)
+}
+
+//	AddAutoscalingV1HorizontalPodAutoscalerRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddAutoscalingV1HorizontalPodAutoscalerRule(rules ...*AutoscalingV1HorizontalPodAutoscalerRule) {
+	l.autoscalingV1HPARules = append(l.autoscalingV1HPARules, rules...)
+}
+
+//	AddAutoscalingV2beta2HorizontalPodAutoscalerRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddAutoscalingV2beta2HorizontalPodAutoscalerRule(rules ...*AutoscalingV2beta2HorizontalPodAutoscalerRule) {
+	l.autoscalingV2beta2HPARules = append(l.autoscalingV2beta2HPARules, rules...)
+}
+
+//	AddPolicyV1beta1PodDisruptionBudgetRule adds a custom rule (or many) so that anything sent through the linter of the correct type
+//	has this rule applied to it.
+func (l *Linter) AddPolicyV1beta1PodDisruptionBudgetRule(rules ...*PolicyV1beta1PodDisruptionBudgetRule) {
+	l.policyV1beta1PDBRules = append(l.policyV1beta1PDBRules, rules...)
+}
+
 //	AddGenericRule adds a custom rule (or many) so that anything sent through the linter
 //	has this rule applied to it.
 func (l *Linter) AddGenericRule(rules ...*GenericRule) {
@@ -403,3 +520,19 @@ func (l *Linter) AddGenericRule(rules ...*GenericRule) {
 func (l *Linter) AddInterdependentRule(rules ...*InterdependentRule) {
 	l.interdependentRules = append(l.interdependentRules, rules...)
 }
+
+//	AddProfile adds every rule in the profile (or many profiles) to the linter,
+//	and tags the results of those rules with the Pod Security Standards control they check.
+func (l *Linter) AddProfile(profiles ...*Profile) {
+	for _, profile := range profiles {
+		l.AddGenericRule(profile.GenericRules...)
+		l.AddWorkloadV1PodSpecRule(profile.PodSpecRules...)
+		l.AddWorkloadV1ContainerRule(profile.ContainerRules...)
+		if l.controls == nil {
+			l.controls = make(map[RuleID]string)
+		}
+		for id, control := range profile.Controls {
+			l.controls[id] = control
+		}
+	}
+}
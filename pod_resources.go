@@ -0,0 +1,99 @@
+package kubelint
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// containerRequests gives you the container's requests the way the API server will see them,
+// with any resource that has a limit but no request requesting its limit.
+func containerRequests(container *v1.Container) v1.ResourceList {
+	requests := container.Resources.Requests.DeepCopy()
+	for name, limit := range container.Resources.Limits {
+		if _, found := requests[name]; !found {
+			if requests == nil {
+				requests = make(v1.ResourceList)
+			}
+			requests[name] = limit.DeepCopy()
+		}
+	}
+	return requests
+}
+
+// addResources adds every quantity in the list to the total.
+func addResources(total v1.ResourceList, list v1.ResourceList) {
+	for name, quantity := range list {
+		sum := quantity.DeepCopy()
+		if current, found := total[name]; found {
+			sum.Add(current)
+		}
+		total[name] = sum
+	}
+}
+
+// maxResources raises every quantity in the total to the one in the list, if that's bigger.
+func maxResources(total v1.ResourceList, list v1.ResourceList) {
+	for name, quantity := range list {
+		if current, found := total[name]; !found || quantity.Cmp(current) == 1 {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// podResources adds up the requests and limits of the pod the way the scheduler does: the containers run together,
+// so their resources are summed, but the init containers run one at a time before them, so only the biggest one counts.
+func podResources(podSpec *v1.PodSpec) (v1.ResourceList, v1.ResourceList) {
+	requests, limits := make(v1.ResourceList), make(v1.ResourceList)
+	for i := range podSpec.Containers {
+		addResources(requests, containerRequests(&podSpec.Containers[i]))
+		addResources(limits, podSpec.Containers[i].Resources.Limits)
+	}
+	for i := range podSpec.InitContainers {
+		maxResources(requests, containerRequests(&podSpec.InitContainers[i]))
+		maxResources(limits, podSpec.InitContainers[i].Resources.Limits)
+	}
+	return requests, limits
+}
+
+// PodQOSClass works out the quality of service class Kubernetes will give the pod
+// (see https://kubernetes.io/docs/tasks/configure-pod-container/quality-service-pod/):
+//
+// - Guaranteed if every container (init containers included) has CPU and memory limits, and requests that are the same
+//
+// - BestEffort if no container has any CPU or memory requests or limits
+//
+// - Burstable otherwise
+func PodQOSClass(podSpec *v1.PodSpec) v1.PodQOSClass {
+	guaranteed, bestEffort := true, true
+	for _, container := range containersOf(podSpec) {
+		requests := containerRequests(&container)
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			request, requested := requests[name]
+			limit, limited := container.Resources.Limits[name]
+			requested = requested && !request.IsZero()
+			limited = limited && !limit.IsZero()
+			if requested || limited {
+				bestEffort = false
+			}
+			if !limited || !requested || request.Cmp(limit) != 0 {
+				guaranteed = false
+			}
+		}
+	}
+	switch {
+	case bestEffort:
+		return v1.PodQOSBestEffort
+	case guaranteed:
+		return v1.PodQOSGuaranteed
+	}
+	return v1.PodQOSBurstable
+}
+
+// quantityRatio divides one quantity by another, in millis for CPU (where cores are usually fractional)
+// and whole units for everything else (so memory in bytes doesn't overflow).
+func quantityRatio(name v1.ResourceName, numerator resource.Quantity, denominator resource.Quantity) float64 {
+	if name == v1.ResourceCPU {
+		return float64(numerator.MilliValue()) / float64(denominator.MilliValue())
+	}
+	return float64(numerator.Value()) / float64(denominator.Value())
+}
@@ -0,0 +1,778 @@
+package kubelint
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
+	networkingV1 "k8s.io/api/networking/v1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	rbacV1 "k8s.io/api/rbac/v1"
+	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The predefined interdependent rules that check how the resources in a unit refer to each other.
+// They're listed along with the rest of the predefined rules in predefined_rules.go.
+var (
+	// A Service's selector should match the pod labels of a workload in the unit
+	INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD = &InterdependentRule{
+		ID: "INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Kind("Service") {
+				service, ok := resource.Object.(*v1.Service)
+				if !ok || !hasSelector(service) || len(graph.From(resource, EdgeSelects)) != 0 {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message: fmt.Sprintf("%s selects %s, but no workload in namespace %q has pods with those labels",
+						kindAndName(resource), labels.Set(service.Spec.Selector), service.Namespace),
+				})
+			}
+			return violations
+		},
+		Message: "A service's selector should match the labels of a workload's pod template in the same namespace",
+		Level:   log.ErrorLevel,
+	}
+	// A Service's targetPort should be a port on a container it selects
+	INTERDEPENDENT_SERVICE_TARGET_PORT_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_SERVICE_TARGET_PORT_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Kind("Service") {
+				service, ok := resource.Object.(*v1.Service)
+				if !ok {
+					continue
+				}
+				var podSpecs []*v1.PodSpec
+				for _, edge := range graph.From(resource, EdgeSelects) {
+					_, podSpec, _ := podTemplate(edge.To.Object)
+					podSpecs = append(podSpecs, podSpec)
+				}
+				if len(podSpecs) == 0 {
+					continue // INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD reports this
+				}
+				for _, port := range service.Spec.Ports {
+					if targetPortExists(port, podSpecs) {
+						continue
+					}
+					targetPort := port.TargetPort.String()
+					if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+						targetPort = fmt.Sprint(port.Port)
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   fmt.Sprintf("%s: port %d targets %s, which isn't a port on any of the containers it selects", kindAndName(resource), port.Port, targetPort),
+					})
+				}
+			}
+			return violations
+		},
+		Message: "A service's targetPort should be the number or name of a port on one of the containers it selects",
+		Level:   log.ErrorLevel,
+	}
+	// A RoleBinding or ClusterRoleBinding should refer to a Role or ClusterRole in the unit, or a built-in ClusterRole (see BuiltInClusterRoles)
+	INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				binding, ok := asRoleBinding(resource.Object)
+				if !ok || roleExists(graph, resource, binding) {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   fmt.Sprintf("%s refers to %s %q, which isn't in the unit", kindAndName(resource), binding.roleRef.Kind, binding.roleRef.Name),
+				})
+			}
+			return violations
+		},
+		Message: "A role binding's roleRef should refer to a Role or ClusterRole in the unit, or to a built-in ClusterRole",
+		Level:   log.ErrorLevel,
+	}
+	// The ServiceAccounts a RoleBinding or ClusterRoleBinding binds to should be in the unit
+	INTERDEPENDENT_ROLE_BINDING_SERVICE_ACCOUNT_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_ROLE_BINDING_SERVICE_ACCOUNT_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				binding, ok := asRoleBinding(resource.Object)
+				if !ok {
+					continue
+				}
+				for i, subject := range binding.subjects {
+					if subject.Kind != rbacV1.ServiceAccountKind {
+						continue
+					}
+					namespace := subject.Namespace
+					if namespace == "" {
+						namespace = binding.namespace
+					}
+					if serviceAccountExists(graph, subject.Name, namespace) {
+						continue
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   fmt.Sprintf("%s: subjects[%d] refers to ServiceAccount %s/%s, which isn't in the unit", kindAndName(resource), i, namespace, subject.Name),
+					})
+				}
+			}
+			return violations
+		},
+		Message: "Every ServiceAccount a role binding refers to should be in the unit",
+		Level:   log.ErrorLevel,
+	}
+	// The ServiceAccount a workload's pods run as should be in the unit
+	INTERDEPENDENT_WORKLOAD_SERVICE_ACCOUNT_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_WORKLOAD_SERVICE_ACCOUNT_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				_, podSpec, ok := podTemplate(resource.Object)
+				if !ok {
+					continue
+				}
+				name := podSpec.ServiceAccountName
+				if name == "" {
+					name = podSpec.DeprecatedServiceAccount
+				}
+				if name == "" || serviceAccountExists(graph, name, resource.Object.GetNamespace()) {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   fmt.Sprintf("%s runs as ServiceAccount %q, which isn't in the unit", kindAndName(resource), name),
+				})
+			}
+			return violations
+		},
+		Message: "The serviceAccountName of a workload's pod template should refer to a ServiceAccount in the unit",
+		Level:   log.ErrorLevel,
+	}
+	// The ConfigMaps a workload's pods use (unless they're optional) should be in the unit
+	INTERDEPENDENT_CONFIGMAP_REFERENCES_EXIST = referencesExistRule("INTERDEPENDENT_CONFIGMAP_REFERENCES_EXIST", "ConfigMap")
+	// The Secrets a workload's pods use (unless they're optional) should be in the unit
+	INTERDEPENDENT_SECRET_REFERENCES_EXIST = referencesExistRule("INTERDEPENDENT_SECRET_REFERENCES_EXIST", "Secret")
+	// The PersistentVolumeClaims a workload's pods mount should be in the unit
+	INTERDEPENDENT_PVC_REFERENCES_EXIST = referencesExistRule("INTERDEPENDENT_PVC_REFERENCES_EXIST", "PersistentVolumeClaim")
+	// Every workload should be selected by a NetworkPolicy that restricts its ingress
+	INTERDEPENDENT_WORKLOAD_INGRESS_POLICY = &InterdependentRule{
+		ID: "INTERDEPENDENT_WORKLOAD_INGRESS_POLICY",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			return unprotectedWorkloads(graph, "ingress to", func(policy *networkPolicy) bool {
+				return policy.ingress
+			})
+		},
+		Message: "Every workload's pods should be selected by a network policy with an Ingress policy type",
+		Level:   log.ErrorLevel,
+	}
+	// Every workload should be selected by a NetworkPolicy that restricts its egress
+	INTERDEPENDENT_WORKLOAD_EGRESS_POLICY = &InterdependentRule{
+		ID: "INTERDEPENDENT_WORKLOAD_EGRESS_POLICY",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			return unprotectedWorkloads(graph, "egress from", func(policy *networkPolicy) bool {
+				return policy.egress
+			})
+		},
+		Message: "Every workload's pods should be selected by a network policy with an Egress policy type",
+		Level:   log.ErrorLevel,
+	}
+	// A NetworkPolicy's podSelector should select at least one workload
+	INTERDEPENDENT_NETWORK_POLICY_SELECTS_WORKLOAD = &InterdependentRule{
+		ID: "INTERDEPENDENT_NETWORK_POLICY_SELECTS_WORKLOAD",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				policy, ok := asNetworkPolicy(resource.Object)
+				if !ok || policy.empty || len(graph.From(resource, EdgeIsolates)) != 0 {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   fmt.Sprintf("The podSelector of %s doesn't select the pods of any workload in its namespace", kindAndName(resource)),
+				})
+			}
+			return violations
+		},
+		Message: "A network policy's podSelector should select the pods of at least one workload in its namespace",
+		Level:   log.ErrorLevel,
+	}
+	// An Ingress's backends should be ports on Services in the unit
+	INTERDEPENDENT_INGRESS_BACKEND_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_INGRESS_BACKEND_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				backends, _, ok := ingressReferences(resource.Object)
+				if !ok {
+					continue
+				}
+				for _, backend := range backends {
+					namespace := resource.Object.GetNamespace()
+					if servicePortExists(graph, backend.ServiceName, backend.ServicePort, namespace) {
+						continue
+					}
+					message := fmt.Sprintf("%s: %s refers to port %s of Service %q, which isn't in the unit",
+						kindAndName(resource), backend.field, backend.ServicePort.String(), backend.ServiceName)
+					if len(graph.Lookup("Service", namespace, backend.ServiceName)) != 0 {
+						message = fmt.Sprintf("%s: %s refers to Service %q, which has no port %s",
+							kindAndName(resource), backend.field, backend.ServiceName, backend.ServicePort.String())
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   message,
+					})
+				}
+			}
+			return violations
+		},
+		Message: "Every backend of an ingress should be the name or number of a port on a service in the unit",
+		Level:   log.ErrorLevel,
+	}
+	// The Secrets an Ingress's TLS section uses should be in the unit
+	INTERDEPENDENT_INGRESS_TLS_SECRET_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_INGRESS_TLS_SECRET_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				_, secretNames, ok := ingressReferences(resource.Object)
+				if !ok {
+					continue
+				}
+				for _, secretName := range secretNames {
+					// a TLS section without a secret name uses the ingress controller's default certificate
+					if secretName == "" || len(graph.Lookup("Secret", resource.Object.GetNamespace(), secretName)) != 0 {
+						continue
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   fmt.Sprintf("%s: TLS secretName refers to Secret %q, which isn't in the unit", kindAndName(resource), secretName),
+					})
+				}
+			}
+			return violations
+		},
+		Message: "Every TLS secretName of an ingress should refer to a secret in the unit",
+		Level:   log.ErrorLevel,
+	}
+	// The same object shouldn't be declared more than once in a unit, since kubectl apply would silently let the last one win
+	INTERDEPENDENT_NO_DUPLICATE_RESOURCES = &InterdependentRule{
+		ID: "INTERDEPENDENT_NO_DUPLICATE_RESOURCES",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, group := range groupResources(graph.Resources(), objectKey) {
+				if len(group) < 2 {
+					continue
+				}
+				name := group[0].Object.GetName()
+				if namespace := group[0].Object.GetNamespace(); namespace != "" {
+					name = namespace + "/" + name
+				}
+				violations = append(violations, &Violation{
+					Resources: group,
+					Message:   fmt.Sprintf("%s %s is declared %d times", group[0].TypeInfo.GetKind(), name, len(group)),
+				})
+			}
+			return violations
+		},
+		Message: "The same object should only be declared once in a unit, otherwise the last one silently wins",
+		Level:   log.ErrorLevel,
+	}
+	// When a unit has one namespace, objects of the same kind and name shouldn't be in different namespaces
+	INTERDEPENDENT_SAME_NAME_DIFFERENT_NAMESPACE = &InterdependentRule{
+		ID: "INTERDEPENDENT_SAME_NAME_DIFFERENT_NAMESPACE",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			if len(graph.Kind("Namespace")) != 1 {
+				return nil // cuz we don't know that everything should be in one namespace
+			}
+			var violations []*Violation
+			for _, group := range groupResources(graph.Resources(), func(resource *Resource) string {
+				return fmt.Sprintf("%s/%s/%s", resource.TypeInfo.GetAPIVersion(), resource.TypeInfo.GetKind(), resource.Object.GetName())
+			}) {
+				var namespaces []string
+				seen := make(map[string]bool)
+				for _, resource := range group {
+					namespace := fmt.Sprintf("%q", resource.Object.GetNamespace())
+					if !seen[namespace] {
+						seen[namespace] = true
+						namespaces = append(namespaces, namespace)
+					}
+				}
+				if len(namespaces) < 2 {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: group,
+					Message:   fmt.Sprintf("The unit has one namespace, but %s is in namespaces %s", kindAndName(group[0]), strings.Join(namespaces, ", ")),
+				})
+			}
+			return violations
+		},
+		Message: "The unit has one namespace, but there are objects with the same kind and name in different namespaces",
+		Level:   log.WarnLevel,
+	}
+	// The workload a HorizontalPodAutoscaler scales should be in the unit
+	INTERDEPENDENT_HPA_SCALE_TARGET_EXISTS = &InterdependentRule{
+		ID: "INTERDEPENDENT_HPA_SCALE_TARGET_EXISTS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				target, ok := scaleTarget(resource.Object)
+				if !ok || len(graph.From(resource, EdgeScales)) != 0 {
+					continue
+				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   fmt.Sprintf("%s scales %s %q, which isn't in the unit", kindAndName(resource), target.Kind, target.Name),
+				})
+			}
+			return violations
+		},
+		Message: "The scaleTargetRef of a horizontal pod autoscaler should refer to a workload in the unit",
+		Level:   log.ErrorLevel,
+	}
+	// A workload scaled by a HorizontalPodAutoscaler shouldn't set its own replicas
+	INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET = &InterdependentRule{
+		ID: "INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				field, ok := replicas(resource.Object)
+				autoscalers := graph.To(resource, EdgeScales)
+				if !ok || *field == nil || len(autoscalers) == 0 {
+					continue
+				}
+				workload, autoscaler := resource, autoscalers[0].From
+				violations = append(violations, &Violation{
+					Resources: []*Resource{workload},
+					Message: fmt.Sprintf("%s sets replicas to %d, but it's scaled by %s, so every apply resets the number of pods it has scaled to",
+						kindAndName(workload), **field, kindAndName(autoscaler)),
+					Fix: func() bool {
+						*field = nil
+						return true
+					},
+					FixDescription: func() string {
+						return fmt.Sprintf("Removed replicas from %s, since %s scales it", kindAndName(workload), kindAndName(autoscaler))
+					},
+				})
+			}
+			return violations
+		},
+		Message: "A workload scaled by a horizontal pod autoscaler shouldn't set spec.replicas",
+		Level:   log.ErrorLevel,
+	}
+	// A PodDisruptionBudget shouldn't stop every pod of a workload from being evicted, eg by requiring one pod of a single replica workload
+	INTERDEPENDENT_PDB_ALLOWS_EVICTION = &InterdependentRule{
+		ID: "INTERDEPENDENT_PDB_ALLOWS_EVICTION",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				budget, ok := resource.Object.(*policyV1beta1.PodDisruptionBudget)
+				if !ok {
+					continue
+				}
+				for _, edge := range graph.From(resource, EdgeProtects) {
+					pods, ok := expectedReplicas(graph, edge.To)
+					if !ok || pods == 0 {
+						continue
+					}
+					allowed, err := disruptionsAllowed(budget, pods)
+					if err == nil && allowed > 0 {
+						continue
+					}
+					message := fmt.Sprintf("%s doesn't allow any of the %d pods of %s to be evicted, so nodes running them can't be drained",
+						kindAndName(resource), pods, kindAndName(edge.To))
+					if err != nil {
+						message = fmt.Sprintf("%s: %s", kindAndName(resource), err)
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   message,
+					})
+				}
+			}
+			return violations
+		},
+		Message: "A pod disruption budget should allow at least one pod of each workload it covers to be evicted, given how many replicas it has (or its autoscaler's minReplicas)",
+		Level:   log.ErrorLevel,
+	}
+	// What a ServiceAccount can do across all the bindings in the unit shouldn't include wildcards, privilege escalation,
+	// reading secrets or exec'ing into pods
+	INTERDEPENDENT_SERVICE_ACCOUNT_RISKY_PERMISSIONS = &InterdependentRule{
+		ID: "INTERDEPENDENT_SERVICE_ACCOUNT_RISKY_PERMISSIONS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, permissions := range EffectivePermissions(graph) {
+				for _, risky := range riskyPermissions {
+					var bindings []*Resource
+					var grants []string
+					for _, grant := range permissions.Grants {
+						if anyRule(grant.Rules, risky.check) {
+							bindings = append(bindings, grant.Binding)
+							grants = append(grants, fmt.Sprintf("%s (%s)", grant.where(), grant.via()))
+						}
+					}
+					if len(grants) == 0 {
+						continue
+					}
+					violations = append(violations, &Violation{
+						Resources: bindings,
+						Message: fmt.Sprintf("ServiceAccount %s/%s can %s %s",
+							permissions.Namespace, permissions.Name, risky.description, strings.Join(grants, ", ")),
+					})
+				}
+			}
+			return violations
+		},
+		Message: "A service account shouldn't be able to use wildcards, escalate, bind or impersonate, read secrets or exec into pods through the roles bound to it",
+		Level:   log.WarnLevel,
+	}
+	// A resource shouldn't use an API version that's been removed from Kubernetes (make your own for the release you're upgrading to
+	// with NewInterdependentRemovedAPIVersionsRule)
+	INTERDEPENDENT_REMOVED_API_VERSIONS = NewInterdependentRemovedAPIVersionsRule(DefaultRemovedAPIVersionsOptions)
+	// An extensions/v1beta1 Ingress should use networking.k8s.io/v1beta1, which replaces it.
+	// It's an interdependent rule so that its fix goes last, after any fixes to the old object.
+	INTERDEPENDENT_INGRESS_NETWORKING_API = &InterdependentRule{
+		ID: "INTERDEPENDENT_INGRESS_NETWORKING_API",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				ingress, ok := resource.Object.(*v1beta1Extensions.Ingress)
+				if !ok {
+					continue
+				}
+				resource := resource
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   fmt.Sprintf("%s uses extensions/v1beta1, which is deprecated in favour of networking.k8s.io/v1beta1", kindAndName(resource)),
+					Fix: func() bool {
+						converted, err := convertExtensionsIngress(ingress)
+						if err != nil {
+							return false
+						}
+						replacement, err := ConvertToResource(converted)
+						if err != nil {
+							return false
+						}
+						*resource = *replacement
+						return true
+					},
+					FixDescription: func() string {
+						return fmt.Sprintf("Converted %s to networking.k8s.io/v1beta1", kindAndName(resource))
+					},
+				})
+			}
+			return violations
+		},
+		Message: "An ingress should use the networking.k8s.io/v1beta1 API rather than extensions/v1beta1",
+		Level:   log.WarnLevel,
+	}
+)
+
+// kindAndName describes a resource for a message, eg "Deployment pear"
+func kindAndName(resource *Resource) string {
+	return fmt.Sprintf("%s %s", resource.TypeInfo.GetKind(), resource.Object.GetName())
+}
+
+// hasSelector tells you if the service sends traffic to pods,
+// rather than to an external name or endpoints that are managed by hand.
+func hasSelector(service *v1.Service) bool {
+	return service.Spec.Type != v1.ServiceTypeExternalName && len(service.Spec.Selector) != 0
+}
+
+// targetPortExists tells you if the port's targetPort (which defaults to the port itself) names or numbers
+// a port on one of the containers.
+func targetPortExists(port v1.ServicePort, podSpecs []*v1.PodSpec) bool {
+	for _, podSpec := range podSpecs {
+		for _, container := range podSpec.Containers {
+			for _, containerPort := range container.Ports {
+				switch {
+				case port.TargetPort.Type == intstr.String:
+					if containerPort.Name == port.TargetPort.StrVal {
+						return true
+					}
+				case port.TargetPort.IntVal == 0:
+					if containerPort.ContainerPort == port.Port {
+						return true
+					}
+				default:
+					if containerPort.ContainerPort == port.TargetPort.IntVal {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// roleBinding holds the parts of a RoleBinding or ClusterRoleBinding (of any API version) that refer to other resources.
+type roleBinding struct {
+	cluster   bool   // true if it's a ClusterRoleBinding
+	namespace string // the namespace of a RoleBinding
+	roleRef   rbacV1.RoleRef
+	subjects  []rbacV1.Subject
+}
+
+// asRoleBinding gives you the roleBinding for a RoleBinding or ClusterRoleBinding.
+// The last return value is false if the object isn't a role binding at all.
+func asRoleBinding(object metav1.Object) (*roleBinding, bool) {
+	switch binding := object.(type) {
+	case *rbacV1.RoleBinding:
+		return &roleBinding{namespace: binding.Namespace, roleRef: binding.RoleRef, subjects: binding.Subjects}, true
+	case *rbacV1.ClusterRoleBinding:
+		return &roleBinding{cluster: true, roleRef: binding.RoleRef, subjects: binding.Subjects}, true
+	case *rbacV1beta1.RoleBinding:
+		return &roleBinding{namespace: binding.Namespace, roleRef: rbacV1.RoleRef(binding.RoleRef), subjects: convertSubjects(binding.Subjects)}, true
+	case *rbacV1beta1.ClusterRoleBinding:
+		return &roleBinding{cluster: true, roleRef: rbacV1.RoleRef(binding.RoleRef), subjects: convertSubjects(binding.Subjects)}, true
+	}
+	return nil, false
+}
+
+func convertSubjects(subjects []rbacV1beta1.Subject) []rbacV1.Subject {
+	var converted []rbacV1.Subject
+	for _, subject := range subjects {
+		converted = append(converted, rbacV1.Subject(subject))
+	}
+	return converted
+}
+
+// roleExists tells you if the role the binding refers to is in the unit or is built into kubernetes.
+// A RoleBinding can refer to a Role in its own namespace, or a ClusterRole. A ClusterRoleBinding can only refer to a ClusterRole.
+func roleExists(graph *ResourceGraph, resource *Resource, binding *roleBinding) bool {
+	switch binding.roleRef.Kind {
+	case "Role":
+		return !binding.cluster && len(graph.From(resource, EdgeBindsRole)) != 0
+	case "ClusterRole":
+		return isBuiltInClusterRole(binding.roleRef.Name) || len(graph.From(resource, EdgeBindsRole)) != 0
+	}
+	return false
+}
+
+// BuiltInClusterRoles are the ClusterRoles INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS assumes every cluster has, on top of the system: ones.
+// Add to it if your clusters come with others, eg ones installed by your cloud provider.
+var BuiltInClusterRoles = []string{"cluster-admin", "admin", "edit", "view"}
+
+// isBuiltInClusterRole tells you if the cluster role is one that every cluster comes with.
+func isBuiltInClusterRole(name string) bool {
+	for _, role := range BuiltInClusterRoles {
+		if name == role {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, "system:")
+}
+
+// serviceAccountExists tells you if the service account is in the unit.
+// Every namespace gets a default service account, so that one always exists.
+func serviceAccountExists(graph *ResourceGraph, name string, namespace string) bool {
+	return name == "default" || len(graph.Lookup("ServiceAccount", namespace, name)) != 0
+}
+
+// referencesExistRule creates a rule checking that every non-optional reference to a resource of the given kind
+// in a workload's pod template can be found in the same namespace in the unit.
+// Each missing reference is reported separately, saying which container and field it's in.
+func referencesExistRule(id RuleID, kind string) *InterdependentRule {
+	return &InterdependentRule{
+		ID: id,
+		Violations: func(graph *ResourceGraph) []*Violation {
+			var violations []*Violation
+			for _, resource := range graph.Resources() {
+				_, podSpec, ok := podTemplate(resource.Object)
+				if !ok {
+					continue
+				}
+				for _, reference := range podReferences(podSpec) {
+					if reference.kind != kind || reference.optional || len(graph.Lookup(kind, resource.Object.GetNamespace(), reference.name)) != 0 {
+						continue
+					}
+					violations = append(violations, &Violation{
+						Resources: []*Resource{resource},
+						Message:   fmt.Sprintf("%s: %s, which isn't in the unit", kindAndName(resource), reference),
+					})
+				}
+			}
+			return violations
+		},
+		Message: fmt.Sprintf("Every %s a workload refers to should be in the unit, unless the reference is optional", kind),
+		Level:   log.ErrorLevel,
+	}
+}
+
+// networkPolicy holds the parts of a NetworkPolicy (of any API version) that say which pods it applies to.
+type networkPolicy struct {
+	selector labels.Selector
+	empty    bool // true if the podSelector is empty, so it selects every pod in the namespace
+	ingress  bool // true if it restricts traffic into the pods
+	egress   bool // true if it restricts traffic out of the pods
+}
+
+// asNetworkPolicy gives you the networkPolicy for a networking/v1 or extensions/v1beta1 NetworkPolicy.
+// The last return value is false if the object isn't a network policy, or its podSelector is invalid.
+func asNetworkPolicy(object metav1.Object) (*networkPolicy, bool) {
+	var podSelector metav1.LabelSelector
+	var policyTypes []string
+	var hasEgressRules bool
+	switch policy := object.(type) {
+	case *networkingV1.NetworkPolicy:
+		podSelector = policy.Spec.PodSelector
+		for _, policyType := range policy.Spec.PolicyTypes {
+			policyTypes = append(policyTypes, string(policyType))
+		}
+		hasEgressRules = len(policy.Spec.Egress) != 0
+	case *v1beta1Extensions.NetworkPolicy:
+		podSelector = policy.Spec.PodSelector
+		for _, policyType := range policy.Spec.PolicyTypes {
+			policyTypes = append(policyTypes, string(policyType))
+		}
+		hasEgressRules = len(policy.Spec.Egress) != 0
+	default:
+		return nil, false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&podSelector)
+	if err != nil {
+		return nil, false
+	}
+	policy := &networkPolicy{
+		selector: selector,
+		empty:    len(podSelector.MatchLabels) == 0 && len(podSelector.MatchExpressions) == 0,
+	}
+	if len(policyTypes) == 0 {
+		// kubernetes defaults the policy types this way when they aren't given
+		policy.ingress = true
+		policy.egress = hasEgressRules
+	}
+	for _, policyType := range policyTypes {
+		switch policyType {
+		case "Ingress":
+			policy.ingress = true
+		case "Egress":
+			policy.egress = true
+		}
+	}
+	return policy, true
+}
+
+// unprotectedWorkloads reports the workloads whose pods aren't selected by any network policy that satisfies restricts.
+// direction finishes the message, eg "ingress to"
+func unprotectedWorkloads(graph *ResourceGraph, direction string, restricts func(*networkPolicy) bool) []*Violation {
+	var violations []*Violation
+	for _, resource := range graph.Resources() {
+		if _, _, ok := podTemplate(resource.Object); !ok {
+			continue
+		}
+		protected := false
+		for _, edge := range graph.To(resource, EdgeIsolates) {
+			if policy, ok := asNetworkPolicy(edge.From.Object); ok && restricts(policy) {
+				protected = true
+				break
+			}
+		}
+		if !protected {
+			violations = append(violations, &Violation{
+				Resources: []*Resource{resource},
+				Message:   fmt.Sprintf("No network policy restricts %s the pods of %s", direction, kindAndName(resource)),
+			})
+		}
+	}
+	return violations
+}
+
+// ingressBackend is a backend of an Ingress along with where it was found, eg rules[0].http.paths[1].backend
+type ingressBackend struct {
+	networkingV1beta1.IngressBackend
+	field string
+}
+
+// ingressReferences finds the backends and TLS secret names of an extensions/v1beta1 or networking/v1beta1 Ingress.
+// The last return value is false if the object isn't an ingress.
+func ingressReferences(object metav1.Object) ([]ingressBackend, []string, bool) {
+	var backends []ingressBackend
+	var secretNames []string
+	switch ingress := object.(type) {
+	case *v1beta1Extensions.Ingress:
+		if ingress.Spec.Backend != nil {
+			backends = append(backends, ingressBackend{networkingV1beta1.IngressBackend(*ingress.Spec.Backend), "backend"})
+		}
+		for i, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for j, path := range rule.HTTP.Paths {
+				backends = append(backends, ingressBackend{networkingV1beta1.IngressBackend(path.Backend), fmt.Sprintf("rules[%d].http.paths[%d].backend", i, j)})
+			}
+		}
+		for _, tls := range ingress.Spec.TLS {
+			secretNames = append(secretNames, tls.SecretName)
+		}
+	case *networkingV1beta1.Ingress:
+		if ingress.Spec.Backend != nil {
+			backends = append(backends, ingressBackend{*ingress.Spec.Backend, "backend"})
+		}
+		for i, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for j, path := range rule.HTTP.Paths {
+				backends = append(backends, ingressBackend{path.Backend, fmt.Sprintf("rules[%d].http.paths[%d].backend", i, j)})
+			}
+		}
+		for _, tls := range ingress.Spec.TLS {
+			secretNames = append(secretNames, tls.SecretName)
+		}
+	default:
+		return nil, nil, false
+	}
+	return backends, secretNames, true
+}
+
+// servicePortExists tells you if there's a service in the unit with a port of the given name or number.
+func servicePortExists(graph *ResourceGraph, name string, port intstr.IntOrString, namespace string) bool {
+	for _, resource := range graph.Lookup("Service", namespace, name) {
+		service, ok := resource.Object.(*v1.Service)
+		if !ok {
+			continue
+		}
+		for _, servicePort := range service.Spec.Ports {
+			if port.Type == intstr.String && servicePort.Name == port.StrVal {
+				return true
+			}
+			if port.Type == intstr.Int && servicePort.Port == port.IntVal {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// objectKey identifies an object the way the API server does, by its apiVersion, kind, namespace and name.
+func objectKey(resource *Resource) string {
+	return fmt.Sprintf("%s/%s/%s/%s", resource.TypeInfo.GetAPIVersion(), resource.TypeInfo.GetKind(), resource.Object.GetNamespace(), resource.Object.GetName())
+}
+
+// groupResources groups the resources by key, keeping the groups in the order they first appear.
+func groupResources(resources []*Resource, key func(*Resource) string) [][]*Resource {
+	var keys []string
+	groups := make(map[string][]*Resource)
+	for _, resource := range resources {
+		k := key(resource)
+		if _, found := groups[k]; !found {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], resource)
+	}
+	var grouped [][]*Resource
+	for _, k := range keys {
+		grouped = append(grouped, groups[k])
+	}
+	return grouped
+}
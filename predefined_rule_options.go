@@ -0,0 +1,693 @@
+package kubelint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+/*
+
+Some predefined rules check for values that only make sense for one organisation (like which registry images come from).
+They're made by constructors that take options, so you can make the same rule with your own values:
+
+	linter.AddV1ContainerRule(kubelint.NewV1ContainerValidImageRule(kubelint.ImageOptions{
+		AllowedRegistries: []string{"*.dkr.ecr.*.amazonaws.com", "quay.io"},
+	}))
+
+	digests, err := kubelint.ReadImageDigests("digests.yaml")
+	linter.AddV1ContainerRule(kubelint.NewV1ContainerImageNotLatestRule(digests))
+
+	target, err := kubelint.ParseKubernetesVersion("v1.22.3")
+	linter.AddInterdependentRule(kubelint.NewInterdependentRemovedAPIVersionsRule(kubelint.RemovedAPIVersionsOptions{
+		TargetVersion: target,
+	}))
+
+The predefined variables (eg V1_CONTAINER_VALID_IMAGE) are made by the same constructors with the default options.
+The constructors return ordinary rules, so you can still change their Message or Level before adding them.
+
+*/
+
+// IDRange is an inclusive range of user or group IDs, eg {Min: 1000, Max: 1999}.
+type IDRange struct {
+	Min int64
+	Max int64
+}
+
+func (r IDRange) contains(id int64) bool {
+	return r.Min <= id && id <= r.Max
+}
+
+func (r IDRange) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprint(r.Min)
+	}
+	return fmt.Sprintf("between %d and %d", r.Min, r.Max)
+}
+
+// UserGroupIDOptions configures the rule made by NewV1PodSpecUserGroupIDRule.
+type UserGroupIDOptions struct {
+	UserIDs  []IDRange // the user IDs the pod can run as, it can run as any user if this is empty
+	GroupIDs []IDRange // the group IDs the pod can run as, it can run as any group if this is empty
+}
+
+// DefaultUserGroupIDOptions are the options V1_PODSPEC_CORRECT_USER_GROUP_ID is made with.
+var DefaultUserGroupIDOptions = UserGroupIDOptions{
+	UserIDs:  []IDRange{{Min: 44444, Max: 44444}},
+	GroupIDs: []IDRange{{Min: 44444, Max: 44444}},
+}
+
+// idAllowed tells you if the (possibly unset) ID is in one of the ranges. An unset ID is only allowed when there aren't any ranges.
+func idAllowed(id *int64, ranges []IDRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	if id == nil {
+		return false
+	}
+	for _, r := range ranges {
+		if r.contains(*id) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeIDRanges(ranges []IDRange) string {
+	var described []string
+	for _, r := range ranges {
+		described = append(described, r.String())
+	}
+	return strings.Join(described, " or ")
+}
+
+// NewV1PodSpecUserGroupIDRule makes a rule that checks the pod's runAsUser and runAsGroup are in the allowed ranges.
+// Its fix sets them to the start of the first range.
+func NewV1PodSpecUserGroupIDRule(options UserGroupIDOptions) *V1PodSpecRule {
+	users, groups := describeIDRanges(options.UserIDs), describeIDRanges(options.GroupIDs)
+	var message, fixDescription string
+	switch {
+	case users == groups:
+		message = fmt.Sprintf("The user and group ID of the podspec should be set to %s", users)
+	case len(options.GroupIDs) == 0:
+		message = fmt.Sprintf("The user ID of the podspec should be set to %s", users)
+	case len(options.UserIDs) == 0:
+		message = fmt.Sprintf("The group ID of the podspec should be set to %s", groups)
+	default:
+		message = fmt.Sprintf("The user ID of the podspec should be set to %s, and its group ID to %s", users, groups)
+	}
+	if len(options.UserIDs) != 0 && len(options.GroupIDs) != 0 && options.UserIDs[0].Min == options.GroupIDs[0].Min {
+		fixDescription = fmt.Sprintf("Set pod's User and Group ID to %d", options.UserIDs[0].Min)
+	} else {
+		var set []string
+		if len(options.UserIDs) != 0 {
+			set = append(set, fmt.Sprintf("User ID to %d", options.UserIDs[0].Min))
+		}
+		if len(options.GroupIDs) != 0 {
+			set = append(set, fmt.Sprintf("Group ID to %d", options.GroupIDs[0].Min))
+		}
+		fixDescription = "Set pod's " + strings.Join(set, " and ")
+	}
+	return &V1PodSpecRule{
+		ID:      "V1_PODSPEC_CORRECT_USER_GROUP_ID",
+		Prereqs: []RuleID{"V1_PODSPEC_NON_NIL_SECURITY_CONTEXT"},
+		Condition: func(podSpec *v1.PodSpec) bool {
+			return idAllowed(podSpec.SecurityContext.RunAsUser, options.UserIDs) &&
+				idAllowed(podSpec.SecurityContext.RunAsGroup, options.GroupIDs)
+		},
+		Message: message,
+		Fix: func(podSpec *v1.PodSpec) bool {
+			if podSpec.SecurityContext == nil {
+				podSpec.SecurityContext = &v1.PodSecurityContext{}
+			}
+			if !idAllowed(podSpec.SecurityContext.RunAsUser, options.UserIDs) {
+				userId := options.UserIDs[0].Min
+				podSpec.SecurityContext.RunAsUser = &userId
+			}
+			if !idAllowed(podSpec.SecurityContext.RunAsGroup, options.GroupIDs) {
+				groupId := options.GroupIDs[0].Min
+				podSpec.SecurityContext.RunAsGroup = &groupId
+			}
+			return true
+		},
+		Level: log.ErrorLevel,
+		FixDescription: func(podSpec *v1.PodSpec) string {
+			return fixDescription
+		},
+	}
+}
+
+// ImageOptions configures the rule made by NewV1ContainerValidImageRule.
+// An image is allowed if its registry matches one of AllowedRegistries, or the whole image matches one of AllowedImages,
+// and it doesn't match any of the denied patterns. If there aren't any allowed patterns, every image that isn't denied is allowed.
+type ImageOptions struct {
+	AllowedRegistries []string         // glob patterns (see path.Match) for the registry's host, eg "*.dkr.ecr.*.amazonaws.com". Images without one come from docker.io
+	AllowedImages     []*regexp.Regexp // patterns for the whole image reference, eg ^quay\.io/coreos/
+	DeniedRegistries  []string         // glob patterns for registries that are never allowed
+	DeniedImages      []*regexp.Regexp // patterns for image references that are never allowed
+}
+
+// DefaultImageOptions are the options V1_CONTAINER_VALID_IMAGE is made with.
+var DefaultImageOptions = ImageOptions{
+	AllowedRegistries: []string{"277433404353.dkr.ecr.eu-central-1.amazonaws.com"},
+}
+
+func registryMatches(patterns []string, registry string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, registry); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func imageMatches(patterns []*regexp.Regexp, image string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(image) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o ImageOptions) allows(image string) bool {
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return false
+	}
+	if registryMatches(o.DeniedRegistries, reference.Registry) || imageMatches(o.DeniedImages, image) {
+		return false
+	}
+	if len(o.AllowedRegistries) == 0 && len(o.AllowedImages) == 0 {
+		return true
+	}
+	return registryMatches(o.AllowedRegistries, reference.Registry) || imageMatches(o.AllowedImages, image)
+}
+
+// NewV1ContainerValidImageRule makes a rule that checks the container's image comes from an allowed registry, or is an allowed image.
+func NewV1ContainerValidImageRule(options ImageOptions) *V1ContainerRule {
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_VALID_IMAGE",
+		Condition: func(container *v1.Container) bool {
+			return options.allows(container.Image)
+		},
+		Message: "The container's image was not from the set of allowed images",
+		Level:   log.ErrorLevel,
+	}
+}
+
+// RequiredLabelsOptions configures the rule made by NewAppsV1DeploymentRequiredLabelsRule.
+type RequiredLabelsOptions struct {
+	ID      RuleID                    // the rule's ID, so you can require different labels in different rules. Defaults to APPSV1_DEPLOYMENT_REQUIRED_LABELS
+	Labels  []string                  // the keys of the labels the deployment's pod template should have
+	Values  map[string]*regexp.Regexp // the patterns the values of some of those labels should match (optional)
+	Renames map[string]string         // the old label each missing label can be renamed from by the fix, eg "app.kubernetes.io/name": "app" (optional)
+	Message string                    // used instead of the message listing the labels (optional)
+}
+
+// NewAppsV1DeploymentRequiredLabelsRule makes a rule that checks the deployment's spec.template.labels has every label,
+// with a value matching its pattern if it has one. If there are Renames, its fix renames the old labels to the missing ones.
+func NewAppsV1DeploymentRequiredLabelsRule(options RequiredLabelsOptions) *AppsV1DeploymentRule {
+	id := options.ID
+	if id == "" {
+		id = "APPSV1_DEPLOYMENT_REQUIRED_LABELS"
+	}
+	var required []string
+	for _, key := range options.Labels {
+		if pattern, found := options.Values[key]; found {
+			required = append(required, fmt.Sprintf("%s (matching %s)", key, pattern))
+		} else {
+			required = append(required, key)
+		}
+	}
+	noun := "label"
+	if len(required) > 1 {
+		noun = "labels"
+	}
+	message := options.Message
+	if message == "" {
+		message = fmt.Sprintf("There should be the %s %s present under the deployment's spec.template.labels", noun, strings.Join(required, ", "))
+	}
+	rule := &AppsV1DeploymentRule{
+		ID: id,
+		Condition: func(deployment *appsv1.Deployment) bool {
+			for _, key := range options.Labels {
+				value, found := deployment.Spec.Template.Labels[key]
+				if !found {
+					return false
+				}
+				if pattern, found := options.Values[key]; found && !pattern.MatchString(value) {
+					return false
+				}
+			}
+			return true
+		},
+		Message: message,
+		Level:   log.ErrorLevel,
+	}
+	if len(options.Renames) == 0 {
+		return rule
+	}
+	// the descriptions of the labels the fix renamed in each deployment, kept until FixDescription asks for them.
+	// The rule is shared by every session, so sessions fixing deployments at the same time mustn't see each other's.
+	var mutex sync.Mutex
+	renames := make(map[*appsv1.Deployment][]string)
+	rule.Fix = func(deployment *appsv1.Deployment) bool {
+		var renamed []string
+		for _, key := range options.Labels {
+			from, found := options.Renames[key]
+			if !found {
+				continue
+			}
+			if _, found := deployment.Spec.Template.Labels[key]; found {
+				continue
+			}
+			label, found := deployment.Spec.Template.Labels[from]
+			if !found {
+				continue
+			}
+			delete(deployment.Spec.Template.Labels, from)
+			deployment.Spec.Template.Labels[key] = label
+			renamed = append(renamed, fmt.Sprintf("Found %s label in deployment %s and used this value to populate the %q key", from, deployment.Name, key))
+		}
+		if len(renamed) == 0 {
+			return false
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		renames[deployment] = renamed
+		return true
+	}
+	rule.FixDescription = func(deployment *appsv1.Deployment) string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		renamed := renames[deployment]
+		delete(renames, deployment)
+		return strings.Join(renamed, "; ")
+	}
+	return rule
+}
+
+// CPUOptions configures the rule made by NewV1ContainerCPURule. A cap of zero isn't checked.
+type CPUOptions struct {
+	MaxRequest resource.Quantity // the most CPU a container can request, eg resource.MustParse("500m")
+	MaxLimit   resource.Quantity // the highest CPU limit a container can have
+	Message    string            // used instead of the message listing the caps (optional)
+}
+
+// DefaultCPUOptions are the options V1_CONTAINER_REQUESTS_CPU_REASONABLE is made with.
+var DefaultCPUOptions = CPUOptions{
+	MaxRequest: resource.MustParse("1"),
+}
+
+// NewV1ContainerCPURule makes a rule that checks the container's CPU request and limit aren't above the caps.
+// Without either cap there's nothing to check, so the rule always passes.
+func NewV1ContainerCPURule(options CPUOptions) *V1ContainerRule {
+	var caps []string
+	if !options.MaxRequest.IsZero() {
+		caps = append(caps, fmt.Sprintf("request no more than %s", options.MaxRequest.String()))
+	}
+	if !options.MaxLimit.IsZero() {
+		caps = append(caps, fmt.Sprintf("limit it to no more than %s", options.MaxLimit.String()))
+	}
+	message := options.Message
+	switch {
+	case message != "":
+	case len(caps) == 0:
+		// nothing is checked so it never fails, but the message should still read properly
+		message = "You should request a reasonable amount of CPU"
+	default:
+		message = fmt.Sprintf("You should %s CPU", strings.Join(caps, " and "))
+	}
+	return &V1ContainerRule{
+		ID:      "V1_CONTAINER_REQUESTS_CPU_REASONABLE",
+		Prereqs: []RuleID{"V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS"},
+		Condition: func(container *v1.Container) bool {
+			if !options.MaxRequest.IsZero() && container.Resources.Requests.Cpu().Cmp(options.MaxRequest) == 1 {
+				return false
+			}
+			if !options.MaxLimit.IsZero() && container.Resources.Limits.Cpu().Cmp(options.MaxLimit) == 1 {
+				return false
+			}
+			return true
+		},
+		Message: message,
+		Level:   log.ErrorLevel,
+	}
+}
+
+// pinImageFix makes a fix that rewrites the container's image to the digest it's mapped to.
+// It can't fix images that aren't in the mapping.
+func pinImageFix(digests ImageDigests) (func(*v1.Container) bool, func(*v1.Container) string) {
+	fix := func(container *v1.Container) bool {
+		reference, err := ParseImageReference(container.Image)
+		if err != nil {
+			return false
+		}
+		digest, found := digests.digestFor(reference)
+		if !found {
+			return false
+		}
+		container.Image = pinImage(container.Image, reference, digest)
+		return true
+	}
+	fixDescription := func(container *v1.Container) string {
+		return fmt.Sprintf("Pinned container %s's image to %s", container.Name, container.Image)
+	}
+	return fix, fixDescription
+}
+
+// NewV1ContainerImageNotLatestRule makes a rule that checks the container's image has a tag other than latest, or a digest.
+// Its fix pins the image to the digest it's mapped to in digests (which can be nil if you don't want a fix).
+func NewV1ContainerImageNotLatestRule(digests ImageDigests) *V1ContainerRule {
+	fix, fixDescription := pinImageFix(digests)
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_IMAGE_NOT_LATEST",
+		Condition: func(container *v1.Container) bool {
+			reference, err := ParseImageReference(container.Image)
+			return err == nil && !reference.IsLatest()
+		},
+		Message:        "The container's image should have a tag other than latest, or be pinned to a digest",
+		Level:          log.ErrorLevel,
+		Fix:            fix,
+		FixDescription: fixDescription,
+	}
+}
+
+// NewV1ContainerImageDigestRule makes a rule that checks the container's image is pinned to a digest (eg nginx@sha256:...).
+// Its fix pins the image to the digest it's mapped to in digests (which can be nil if you don't want a fix).
+func NewV1ContainerImageDigestRule(digests ImageDigests) *V1ContainerRule {
+	fix, fixDescription := pinImageFix(digests)
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_IMAGE_DIGEST_PINNED",
+		Condition: func(container *v1.Container) bool {
+			reference, err := ParseImageReference(container.Image)
+			return err == nil && reference.Digest != ""
+		},
+		Message:        "The container's image should be pinned to a digest",
+		Level:          log.ErrorLevel,
+		Fix:            fix,
+		FixDescription: fixDescription,
+	}
+}
+
+// LimitRequestRatioOptions configures the rule made by NewV1ContainerLimitRequestRatioRule.
+type LimitRequestRatioOptions struct {
+	MaxRatios map[v1.ResourceName]float64 // how many times its request a container's limit can be, for each resource, eg {cpu: 4}
+}
+
+// DefaultLimitRequestRatioOptions are the options V1_CONTAINER_LIMIT_REQUEST_RATIO is made with.
+var DefaultLimitRequestRatioOptions = LimitRequestRatioOptions{
+	MaxRatios: map[v1.ResourceName]float64{v1.ResourceCPU: 4, v1.ResourceMemory: 2},
+}
+
+// NewV1ContainerLimitRequestRatioRule makes a rule that checks the container's limits aren't too far above its requests,
+// so it can't be scheduled on a node that can't really run it. Resources without a limit aren't checked.
+func NewV1ContainerLimitRequestRatioRule(options LimitRequestRatioOptions) *V1ContainerRule {
+	var names []string
+	for name := range options.MaxRatios {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	var bounds []string
+	for _, name := range names {
+		bounds = append(bounds, fmt.Sprintf("%g times its %s request", options.MaxRatios[v1.ResourceName(name)], name))
+	}
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_LIMIT_REQUEST_RATIO",
+		Condition: func(container *v1.Container) bool {
+			requests := containerRequests(container)
+			for name, maxRatio := range options.MaxRatios {
+				limit, limited := container.Resources.Limits[name]
+				request, requested := requests[name]
+				if !limited {
+					continue
+				}
+				if !requested || request.IsZero() || quantityRatio(name, limit, request) > maxRatio {
+					return false
+				}
+			}
+			return true
+		},
+		Message: fmt.Sprintf("The container's limits should be at most %s", strings.Join(bounds, ", and ")),
+		Level:   log.ErrorLevel,
+	}
+}
+
+// PodResourceTotalsOptions configures the rule made by NewV1PodSpecResourceTotalsRule. Resources that aren't in a list aren't checked.
+type PodResourceTotalsOptions struct {
+	MaxRequests v1.ResourceList // the most the pod can request in total, eg {cpu: 4, memory: 8Gi}
+	MaxLimits   v1.ResourceList // the highest the pod's limits can add up to
+}
+
+// DefaultPodResourceTotalsOptions are the options V1_PODSPEC_RESOURCE_TOTALS is made with.
+var DefaultPodResourceTotalsOptions = PodResourceTotalsOptions{
+	MaxRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")},
+	MaxLimits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), v1.ResourceMemory: resource.MustParse("16Gi")},
+}
+
+func describeResourceList(list v1.ResourceList) string {
+	var described []string
+	for name, quantity := range list {
+		described = append(described, fmt.Sprintf("%s %s", quantity.String(), name))
+	}
+	sort.Strings(described)
+	return strings.Join(described, ", ")
+}
+
+// NewV1PodSpecResourceTotalsRule makes a rule that checks what the whole pod requests (and is limited to) isn't above the caps.
+// The containers are added up, and the biggest init container counts if it's bigger, since that's how the scheduler sees the pod.
+func NewV1PodSpecResourceTotalsRule(options PodResourceTotalsOptions) *V1PodSpecRule {
+	var caps []string
+	if len(options.MaxRequests) != 0 {
+		caps = append(caps, "request at most "+describeResourceList(options.MaxRequests))
+	}
+	if len(options.MaxLimits) != 0 {
+		caps = append(caps, "be limited to at most "+describeResourceList(options.MaxLimits))
+	}
+	exceeds := func(total v1.ResourceList, caps v1.ResourceList) bool {
+		for name, max := range caps {
+			if quantity, found := total[name]; found && quantity.Cmp(max) == 1 {
+				return true
+			}
+		}
+		return false
+	}
+	return &V1PodSpecRule{
+		ID: "V1_PODSPEC_RESOURCE_TOTALS",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			requests, limits := podResources(podSpec)
+			return !exceeds(requests, options.MaxRequests) && !exceeds(limits, options.MaxLimits)
+		},
+		Message: fmt.Sprintf("The pod's containers should %s in total", strings.Join(caps, " and ")),
+		Level:   log.ErrorLevel,
+	}
+}
+
+// EphemeralStorageOptions configures the rule made by NewV1ContainerEphemeralStorageRule.
+type EphemeralStorageOptions struct {
+	MaxLimit resource.Quantity // the highest ephemeral-storage limit a container can have, it isn't checked if it's zero
+}
+
+// NewV1ContainerEphemeralStorageRule makes a rule that checks the container has an ephemeral-storage limit
+// (so it can't fill up the node's disk), and that it's no more than the cap.
+func NewV1ContainerEphemeralStorageRule(options EphemeralStorageOptions) *V1ContainerRule {
+	message := "The container should have an ephemeral-storage limit"
+	if !options.MaxLimit.IsZero() {
+		message = fmt.Sprintf("The container should have an ephemeral-storage limit of at most %s", options.MaxLimit.String())
+	}
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT",
+		Condition: func(container *v1.Container) bool {
+			limit, found := container.Resources.Limits[v1.ResourceEphemeralStorage]
+			return found && (options.MaxLimit.IsZero() || limit.Cmp(options.MaxLimit) != 1)
+		},
+		Message: message,
+		Level:   log.ErrorLevel,
+	}
+}
+
+// QOSClassOptions configures the rule made by NewV1PodSpecQOSClassRule.
+type QOSClassOptions struct {
+	Allowed []v1.PodQOSClass // the quality of service classes the pod can have, see PodQOSClass
+}
+
+// DefaultQOSClassOptions are the options V1_PODSPEC_QOS_CLASS is made with.
+var DefaultQOSClassOptions = QOSClassOptions{
+	Allowed: []v1.PodQOSClass{v1.PodQOSGuaranteed, v1.PodQOSBurstable},
+}
+
+// NewV1PodSpecQOSClassRule makes a rule that checks the quality of service class Kubernetes will give the pod is allowed.
+func NewV1PodSpecQOSClassRule(options QOSClassOptions) *V1PodSpecRule {
+	var allowed []string
+	for _, class := range options.Allowed {
+		allowed = append(allowed, string(class))
+	}
+	return &V1PodSpecRule{
+		ID: "V1_PODSPEC_QOS_CLASS",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			class := PodQOSClass(podSpec)
+			for _, allowedClass := range options.Allowed {
+				if class == allowedClass {
+					return true
+				}
+			}
+			return false
+		},
+		Message: fmt.Sprintf("The pod's quality of service class should be %s", strings.Join(allowed, " or ")),
+		Level:   log.ErrorLevel,
+	}
+}
+
+// StartupProbeOptions configures the rule made by NewV1ContainerStartupProbeRule.
+type StartupProbeOptions struct {
+	SlowStartSeconds int32 // a container whose liveness probe waits at least this long before it starts is a slow starter, 30 if it's zero
+}
+
+// DefaultStartupProbeOptions are the options V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS is made with.
+var DefaultStartupProbeOptions = StartupProbeOptions{
+	SlowStartSeconds: 30,
+}
+
+// NewV1ContainerStartupProbeRule makes a rule that checks a container that's slow to start has a startup probe,
+// rather than a liveness probe with a long initialDelaySeconds (which also delays noticing it's stuck after every restart).
+func NewV1ContainerStartupProbeRule(options StartupProbeOptions) *V1ContainerRule {
+	if options.SlowStartSeconds == 0 {
+		options.SlowStartSeconds = DefaultStartupProbeOptions.SlowStartSeconds
+	}
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS",
+		Condition: func(container *v1.Container) bool {
+			if container.LivenessProbe == nil || container.LivenessProbe.InitialDelaySeconds < options.SlowStartSeconds {
+				return true
+			}
+			return hasProbeHandler(container.StartupProbe)
+		},
+		Message: fmt.Sprintf("The container's liveness probe waits %d seconds or more to start, it should have a startupProbe instead", options.SlowStartSeconds),
+		Level:   log.WarnLevel,
+	}
+}
+
+// ProbeTimingsOptions configures the rule made by NewV1ContainerProbeTimingsRule. A limit of zero isn't checked.
+// Settings that are left out of a probe are checked with the value Kubernetes uses for them.
+type ProbeTimingsOptions struct {
+	MaxInitialDelaySeconds      int32 // the longest a probe can wait before it starts, use a startup probe for containers that need longer
+	MaxTimeoutSeconds           int32 // the longest a probe can wait for an answer, it should also be less than periodSeconds
+	MinLivenessFailureThreshold int32 // how many times in a row a liveness probe has to fail before the container is restarted, at least
+}
+
+// DefaultProbeTimingsOptions are the options V1_CONTAINER_PROBE_TIMINGS is made with.
+var DefaultProbeTimingsOptions = ProbeTimingsOptions{
+	MaxInitialDelaySeconds:      60,
+	MaxTimeoutSeconds:           10,
+	MinLivenessFailureThreshold: 3,
+}
+
+// NewV1ContainerProbeTimingsRule makes a rule that checks the container's probes don't wait too long to start,
+// have a timeout shorter than how often they run, and don't restart the container after a single failed liveness check.
+func NewV1ContainerProbeTimingsRule(options ProbeTimingsOptions) *V1ContainerRule {
+	var limits []string
+	if options.MaxInitialDelaySeconds != 0 {
+		limits = append(limits, fmt.Sprintf("an initialDelaySeconds of at most %d", options.MaxInitialDelaySeconds))
+	}
+	if options.MaxTimeoutSeconds != 0 {
+		limits = append(limits, fmt.Sprintf("a timeoutSeconds of at most %d (and less than periodSeconds)", options.MaxTimeoutSeconds))
+	} else {
+		limits = append(limits, "a timeoutSeconds less than periodSeconds")
+	}
+	message := fmt.Sprintf("The container's probes should have %s", strings.Join(limits, ", "))
+	if options.MinLivenessFailureThreshold != 0 {
+		message += fmt.Sprintf(", and its liveness probe should have a failureThreshold of at least %d", options.MinLivenessFailureThreshold)
+	}
+	return &V1ContainerRule{
+		ID: "V1_CONTAINER_PROBE_TIMINGS",
+		Condition: func(container *v1.Container) bool {
+			for _, probe := range containerProbes(container) {
+				timeout := orDefault(probe.probe.TimeoutSeconds, defaultProbeTimeoutSeconds)
+				if options.MaxInitialDelaySeconds != 0 && probe.probe.InitialDelaySeconds > options.MaxInitialDelaySeconds {
+					return false
+				}
+				if options.MaxTimeoutSeconds != 0 && timeout > options.MaxTimeoutSeconds {
+					return false
+				}
+				if timeout >= orDefault(probe.probe.PeriodSeconds, defaultProbePeriodSeconds) {
+					return false
+				}
+				if probe.field == "livenessProbe" && options.MinLivenessFailureThreshold != 0 &&
+					orDefault(probe.probe.FailureThreshold, defaultProbeFailureThreshold) < options.MinLivenessFailureThreshold {
+					return false
+				}
+			}
+			return true
+		},
+		Message: message,
+		Level:   log.WarnLevel,
+	}
+}
+
+// RemovedAPIVersionsOptions configures the rules made by NewInterdependentRemovedAPIVersionsRule and NewInterdependentDeprecatedAPIVersionsRule.
+type RemovedAPIVersionsOptions struct {
+	TargetVersion KubernetesVersion // the release you're upgrading to, every removal in the table is reported if this is the zero value
+	Deprecations  []APIDeprecation  // the table of deprecations, APIDeprecations if this is nil
+}
+
+// DefaultRemovedAPIVersionsOptions are the options INTERDEPENDENT_REMOVED_API_VERSIONS is made with.
+var DefaultRemovedAPIVersionsOptions = RemovedAPIVersionsOptions{}
+
+// NewInterdependentRemovedAPIVersionsRule makes a rule that reports every resource whose apiVersion and kind won't be served
+// by the target release, and says which release removed it and what to migrate to. It's an interdependent rule so that
+// each resource gets a message of its own, eg
+// "Deployment pear uses extensions/v1beta1, which was removed in Kubernetes 1.16, migrate to apps/v1"
+func NewInterdependentRemovedAPIVersionsRule(options RemovedAPIVersionsOptions) *InterdependentRule {
+	message := "Every resource should use an API version that's still served by the newest Kubernetes release"
+	if !options.TargetVersion.IsZero() {
+		message = fmt.Sprintf("Every resource should use an API version that's still served by Kubernetes %s", options.TargetVersion)
+	}
+	return &InterdependentRule{
+		ID: "INTERDEPENDENT_REMOVED_API_VERSIONS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			return apiDeprecationViolations(graph, options.Deprecations, func(resource *Resource, deprecation APIDeprecation) string {
+				if !options.TargetVersion.AtLeast(deprecation.RemovedIn) {
+					return ""
+				}
+				return fmt.Sprintf("%s uses %s, which was removed in Kubernetes %s, migrate to %s",
+					kindAndName(resource), deprecation.APIVersion, deprecation.RemovedIn, deprecation.MigrateTo)
+			})
+		},
+		Message: message,
+		Level:   log.ErrorLevel,
+	}
+}
+
+// NewInterdependentDeprecatedAPIVersionsRule makes a rule that warns about every resource whose apiVersion and kind are deprecated,
+// but still served, in the target release, eg
+// "CronJob prune uses batch/v1beta1, which was deprecated in Kubernetes 1.21 and will be removed in 1.25, migrate to batch/v1"
+// Everything in the table has been removed by the newest release, so it never reports anything if the target is the zero value.
+func NewInterdependentDeprecatedAPIVersionsRule(options RemovedAPIVersionsOptions) *InterdependentRule {
+	message := "Every resource should use an API version that isn't deprecated in the newest Kubernetes release"
+	if !options.TargetVersion.IsZero() {
+		message = fmt.Sprintf("Every resource should use an API version that isn't deprecated in Kubernetes %s", options.TargetVersion)
+	}
+	return &InterdependentRule{
+		ID: "INTERDEPENDENT_DEPRECATED_API_VERSIONS",
+		Violations: func(graph *ResourceGraph) []*Violation {
+			return apiDeprecationViolations(graph, options.Deprecations, func(resource *Resource, deprecation APIDeprecation) string {
+				if !options.TargetVersion.AtLeast(deprecation.DeprecatedIn) || options.TargetVersion.AtLeast(deprecation.RemovedIn) {
+					return ""
+				}
+				return fmt.Sprintf("%s uses %s, which was deprecated in Kubernetes %s and will be removed in %s, migrate to %s",
+					kindAndName(resource), deprecation.APIVersion, deprecation.DeprecatedIn, deprecation.RemovedIn, deprecation.MigrateTo)
+			})
+		},
+		Message: message,
+		Level:   log.WarnLevel,
+	}
+}
@@ -3,15 +3,22 @@ package kubelint
 import (
 	"fmt"
 	"regexp"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
 	networkingV1 "k8s.io/api/networking/v1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	rbacV1 "k8s.io/api/rbac/v1"
+	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 /*
@@ -21,15 +28,15 @@ to include more generally applicable rules. If you wish to expand the list, plea
 
 Predefined rules relating to resources of type appsv1.Deployment
 
-- An AppsV1Deployment should have a project label: APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL
+- An AppsV1Deployment should have a project label (require your own with NewAppsV1DeploymentRequiredLabelsRule): APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL
 
 - An AppsV1Deployment should have an app.kubernetes.io/name label: APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL
 
 - An AppsV1Deployment should be within a namespace: APPSV1_DEPLOYMENT_WITHIN_NAMESPACE
 
-- An AppsV1Deployment should specify a liveness endpoint: APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS
+- An AppsV1Deployment should specify a liveness endpoint for every container: APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS
 
-- An AppsV1Deployment should specify a readiness endpoint: APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS
+- An AppsV1Deployment should specify a readiness endpoint for every container: APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS
 
 - An AppsV1Deploument should have liveness and readiness endpoints that aren't the same: APPSV1_DEPLOYMENT_LIVENESS_READINESS_NONMATCHING
 
@@ -39,10 +46,14 @@ Predefined rules relating to resources of type v1.PodSpec
 
 - A V1PodSpec should specify runAsNonRoot: true: V1_PODSPEC_RUN_AS_NON_ROOT
 
-- A V1PodSpec should have a user and group ID of 44444: V1_PODSPEC_CORRECT_USER_GROUP_ID
+- A V1PodSpec should have a user and group ID of 44444 (make your own with NewV1PodSpecUserGroupIDRule): V1_PODSPEC_CORRECT_USER_GROUP_ID
 
 - A V1PodSpec should have exactly one container: V1_PODSPEC_EXACTLY_1_CONTAINER
 
+- A V1PodSpec should request at most 4 CPU and 8Gi of memory in total (make your own with NewV1PodSpecResourceTotalsRule): V1_PODSPEC_RESOURCE_TOTALS
+
+- A V1PodSpec's quality of service class shouldn't be BestEffort (make your own with NewV1PodSpecQOSClassRule): V1_PODSPEC_QOS_CLASS
+
 - A V1PodSpec should have a non-zero number of containers: V1_PODSPEC_NON_ZERO_CONTAINERS
 
 Predefined rules relating to resources of type v1.Container
@@ -51,13 +62,75 @@ Predefined rules relating to resources of type v1.Container
 
 - A V1Container should not allow privilege escalation: V1_CONTAINER_ALLOW_PRIVILEGE_ESCALATION_FALSE
 
-- A V1Container's image should come from a set of allowed images defined in isImageAllowed: V1_CONTAINER_VALID_IMAGE
+- A V1Container's image should come from the registries in DefaultImageOptions (allow or deny your own registries and images with NewV1ContainerValidImageRule): V1_CONTAINER_VALID_IMAGE
+
+- A V1Container should have a liveness probe (HTTP, TCP or exec): V1_CONTAINER_EXISTS_LIVENESS
+
+- A V1Container should have a readiness probe (HTTP, TCP or exec): V1_CONTAINER_EXISTS_READINESS
+
+- A V1Container's liveness and readiness probes shouldn't check the same thing: V1_CONTAINER_LIVENESS_READINESS_NONMATCHING
+
+- A V1Container's HTTP and TCP probes should connect to one of its ports: V1_CONTAINER_PROBE_PORTS_EXIST
+
+- A V1Container whose liveness probe waits 30 seconds or more should have a startup probe (make your own with NewV1ContainerStartupProbeRule): V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS
+
+- A V1Container's probes should have sensible delays, timeouts and failure thresholds (make your own with NewV1ContainerProbeTimingsRule): V1_CONTAINER_PROBE_TIMINGS
+
+- A V1Container's memory limit should be set and be the same as its request: V1_CONTAINER_MEMORY_LIMIT_EQUALS_REQUEST
+
+- A V1Container's limits should be at most 4 (CPU) and 2 (memory) times its requests (make your own with NewV1ContainerLimitRequestRatioRule): V1_CONTAINER_LIMIT_REQUEST_RATIO
+
+- A V1Container should have an ephemeral-storage limit (cap it with NewV1ContainerEphemeralStorageRule): V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT
+
+- A V1Container's image should have a tag other than latest, or a digest (add a fix with NewV1ContainerImageNotLatestRule): V1_CONTAINER_IMAGE_NOT_LATEST
+
+- A V1Container's image should be pinned to a digest (add a fix with NewV1ContainerImageDigestRule): V1_CONTAINER_IMAGE_DIGEST_PINNED
+
+- A V1Container's imagePullPolicy should be Always for latest images and not Always for images pinned to a digest: V1_CONTAINER_IMAGE_PULL_POLICY_CONSISTENT
 
 - A V1Container should have privileged set to false: V1_CONTAINER_PRIVILEGED_FALSE
 
 - A V1Container should specify Resource Limits and Requests: V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS
 
-- A V1Container should make CPU requests that are less than or equal to 100%: V1_CONTAINER_REQUESTS_CPU_REASONABLE
+- A V1Container should make CPU requests that are less than or equal to 100% (make your own with NewV1ContainerCPURule): V1_CONTAINER_REQUESTS_CPU_REASONABLE
+
+Predefined rules implementing the Pod Security Standards, bundled into ProfileBaseline and ProfileRestricted
+
+- A V1PodSpec shouldn't use the host's network, PID or IPC namespace: V1_PODSPEC_PSS_HOST_NAMESPACES
+
+- A V1PodSpec shouldn't mount hostPath volumes: V1_PODSPEC_PSS_HOST_PATH_VOLUMES
+
+- A V1PodSpec's SELinux options should only set an allowed type: V1_PODSPEC_PSS_SELINUX
+
+- A V1PodSpec should only set safe sysctls: V1_PODSPEC_PSS_SYSCTLS
+
+- A V1PodSpec should only use volumes backed by the cluster (restricted): V1_PODSPEC_PSS_VOLUME_TYPES
+
+- A V1PodSpec's containers should all run as non-root (restricted): V1_PODSPEC_PSS_RUN_AS_NON_ROOT
+
+- A V1PodSpec's containers shouldn't set runAsUser to 0 (restricted): V1_PODSPEC_PSS_RUN_AS_NON_ROOT_USER
+
+- A V1Container shouldn't be privileged: V1_CONTAINER_PSS_PRIVILEGED
+
+- A V1Container should only add the default capabilities: V1_CONTAINER_PSS_BASELINE_CAPABILITIES
+
+- A V1Container shouldn't use host ports: V1_CONTAINER_PSS_HOST_PORTS
+
+- A V1Container's SELinux options should only set an allowed type: V1_CONTAINER_PSS_SELINUX
+
+- A V1Container should use the default /proc mount: V1_CONTAINER_PSS_PROC_MOUNT
+
+- A V1Container should set allowPrivilegeEscalation to false (restricted): V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION
+
+- A V1Container should drop ALL capabilities (restricted): V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES
+
+- A V1Container shouldn't add any capability but NET_BIND_SERVICE (restricted): V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES
+
+- A pod's AppArmor annotations should be runtime/default or localhost/<profile>: GENERIC_PSS_APPARMOR
+
+- A pod's seccomp annotations shouldn't be unconfined: GENERIC_PSS_SECCOMP_BASELINE
+
+- A pod's seccomp annotations should confine every container (restricted): GENERIC_PSS_SECCOMP_RESTRICTED
 
 Predefined rules relating to resources of type batchV1.Job
 
@@ -81,6 +154,69 @@ Predefined rules relating to resources of type v1.Service
 
 - A V1Service name should be a valid DNS: V1_SERVICE_NAME_VALID_DNS
 
+Predefined rules relating to resources of type v1.ConfigMap and v1.Secret (they never say what the data is, so credentials don't leak into the results or logs)
+
+- A V1ConfigMap's data shouldn't contain AWS access keys, private keys or random looking tokens: V1_CONFIGMAP_NO_CREDENTIALS
+
+- A V1ConfigMap's data shouldn't be within 10% of the 1MiB limit: V1_CONFIGMAP_SIZE_UNDER_LIMIT
+
+- A V1ConfigMap's keys should be valid, and not be in both data and binaryData: V1_CONFIGMAP_VALID_KEYS
+
+- A V1Secret shouldn't be committed with plaintext stringData: V1_SECRET_NO_STRING_DATA
+
+- A V1Secret's data shouldn't be within 10% of the 1MiB limit: V1_SECRET_SIZE_UNDER_LIMIT
+
+- A V1Secret's keys should be valid: V1_SECRET_VALID_KEYS
+
+Predefined rules relating to resources of type v1beta1Extensions.Ingress and networkingV1beta1.Ingress
+(there's no pathType rule, since the API version kubelint is built against has no pathType field)
+
+- A V1Beta1ExtensionsIngress should serve every host over TLS: V1BETA1_EXTENSIONS_INGRESS_TLS_COVERS_HOSTS
+
+- A V1Beta1ExtensionsIngress shouldn't have rules for wildcard hosts: V1BETA1_EXTENSIONS_INGRESS_NO_WILDCARD_HOSTS
+
+- A V1Beta1ExtensionsIngress should have a kubernetes.io/ingress.class annotation: V1BETA1_EXTENSIONS_INGRESS_EXISTS_CLASS_ANNOTATION
+
+- A NetworkingV1beta1Ingress should serve every host over TLS: NETWORKINGV1BETA1_INGRESS_TLS_COVERS_HOSTS
+
+- A NetworkingV1beta1Ingress shouldn't have rules for wildcard hosts: NETWORKINGV1BETA1_INGRESS_NO_WILDCARD_HOSTS
+
+- A NetworkingV1beta1Ingress should have a kubernetes.io/ingress.class annotation: NETWORKINGV1BETA1_INGRESS_EXISTS_CLASS_ANNOTATION
+
+Predefined rules relating to resources of type rbacV1.Role, rbacV1.ClusterRole, rbacV1.RoleBinding, rbacV1beta1.RoleBinding and rbacV1.ClusterRoleBinding
+
+- A RbacV1Role shouldn't use "*" for verbs or resources: RBACV1_ROLE_NO_WILDCARDS
+
+- A RbacV1Role shouldn't allow escalate, bind or impersonate: RBACV1_ROLE_NO_PRIVILEGE_ESCALATION
+
+- A RbacV1Role shouldn't allow reading secrets: RBACV1_ROLE_NO_SECRETS_READ
+
+- A RbacV1Role shouldn't allow pods/exec: RBACV1_ROLE_NO_POD_EXEC
+
+- A RbacV1ClusterRole shouldn't use "*" for verbs or resources: RBACV1_CLUSTERROLE_NO_WILDCARDS
+
+- A RbacV1ClusterRole shouldn't allow escalate, bind or impersonate: RBACV1_CLUSTERROLE_NO_PRIVILEGE_ESCALATION
+
+- A RbacV1ClusterRole shouldn't allow reading secrets: RBACV1_CLUSTERROLE_NO_SECRETS_READ
+
+- A RbacV1ClusterRole shouldn't allow pods/exec: RBACV1_CLUSTERROLE_NO_POD_EXEC
+
+- A RbacV1RoleBinding shouldn't grant cluster-admin: RBACV1_ROLEBINDING_NOT_CLUSTER_ADMIN
+
+- A RbacV1Beta1RoleBinding shouldn't grant cluster-admin: RBACV1BETA1_ROLEBINDING_NOT_CLUSTER_ADMIN
+
+- A RbacV1ClusterRoleBinding shouldn't grant cluster-admin: RBACV1_CLUSTERROLEBINDING_NOT_CLUSTER_ADMIN
+
+Predefined rules relating to resources of type autoscalingV1.HorizontalPodAutoscaler and autoscalingV2beta2.HorizontalPodAutoscaler
+
+- An AutoscalingV1HorizontalPodAutoscaler's minReplicas shouldn't be more than its maxReplicas: AUTOSCALINGV1_HPA_MIN_REPLICAS_AT_MOST_MAX
+
+- An AutoscalingV2beta2HorizontalPodAutoscaler's minReplicas shouldn't be more than its maxReplicas: AUTOSCALINGV2BETA2_HPA_MIN_REPLICAS_AT_MOST_MAX
+
+Predefined rules relating to resources of type policyV1beta1.PodDisruptionBudget
+
+- A PolicyV1beta1PodDisruptionBudget shouldn't set maxUnavailable to 0 or minAvailable to 100%: POLICYV1BETA1_PDB_ALLOWS_EVICTION
+
 Predefined interdependent rules
 
 - A unit should always contain one namespace: INTERDEPENDENT_ONE_NAMESPACE
@@ -88,40 +224,63 @@ Predefined interdependent rules
 - All resources should be under the namespace in the unit: INTERDEPENDENT_MATCHING_NAMESPACE
 
 - The unit should contain a network policy: INTERDEPENDENT_NETWORK_POLICY_REQUIRED
+
+- A Service's selector should match the pod labels of a workload in the unit: INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD
+
+- A Service's targetPort should be a port on a container it selects: INTERDEPENDENT_SERVICE_TARGET_PORT_EXISTS
+
+- A RoleBinding or ClusterRoleBinding should refer to a Role or ClusterRole in the unit, or a built-in ClusterRole: INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS
+
+- The ServiceAccounts a RoleBinding or ClusterRoleBinding binds to should be in the unit: INTERDEPENDENT_ROLE_BINDING_SERVICE_ACCOUNT_EXISTS
+
+- The ServiceAccount a workload's pods run as should be in the unit: INTERDEPENDENT_WORKLOAD_SERVICE_ACCOUNT_EXISTS
+
+- The ConfigMaps a workload's pods use (unless they're optional) should be in the unit: INTERDEPENDENT_CONFIGMAP_REFERENCES_EXIST
+
+- The Secrets a workload's pods use (unless they're optional) should be in the unit: INTERDEPENDENT_SECRET_REFERENCES_EXIST
+
+- The PersistentVolumeClaims a workload's pods mount should be in the unit: INTERDEPENDENT_PVC_REFERENCES_EXIST
+
+- Every workload should be selected by a NetworkPolicy that restricts its ingress: INTERDEPENDENT_WORKLOAD_INGRESS_POLICY
+
+- Every workload should be selected by a NetworkPolicy that restricts its egress: INTERDEPENDENT_WORKLOAD_EGRESS_POLICY
+
+- A NetworkPolicy's podSelector should select at least one workload: INTERDEPENDENT_NETWORK_POLICY_SELECTS_WORKLOAD
+
+- An Ingress's backends should be ports on Services in the unit: INTERDEPENDENT_INGRESS_BACKEND_EXISTS
+
+- The Secrets an Ingress's TLS section uses should be in the unit: INTERDEPENDENT_INGRESS_TLS_SECRET_EXISTS
+
+- The same object (apiVersion, kind, namespace and name) shouldn't be declared more than once in a unit: INTERDEPENDENT_NO_DUPLICATE_RESOURCES
+
+- When a unit has one namespace, objects of the same kind and name shouldn't be in different namespaces: INTERDEPENDENT_SAME_NAME_DIFFERENT_NAMESPACE
+
+- The workload a HorizontalPodAutoscaler scales should be in the unit: INTERDEPENDENT_HPA_SCALE_TARGET_EXISTS
+
+- A workload scaled by a HorizontalPodAutoscaler shouldn't set its own replicas: INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET
+
+- A PodDisruptionBudget should allow a pod of each workload it covers to be evicted, eg it shouldn't require one pod of a single replica workload: INTERDEPENDENT_PDB_ALLOWS_EVICTION
+
+- A ServiceAccount's effective permissions (see EffectivePermissions) shouldn't include wildcards, escalate, bind or impersonate, reading secrets or pods/exec: INTERDEPENDENT_SERVICE_ACCOUNT_RISKY_PERMISSIONS
+
+- An extensions/v1beta1 Ingress should be migrated to networking.k8s.io/v1beta1 (the fix converts it): INTERDEPENDENT_INGRESS_NETWORKING_API
+
+- A resource shouldn't use an API version that's been removed from Kubernetes (check against the release you're upgrading to with NewInterdependentRemovedAPIVersionsRule): INTERDEPENDENT_REMOVED_API_VERSIONS
 */
 var (
 	// An AppsV1Deployment should have a project label.
-	APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL = &AppsV1DeploymentRule{
-		ID: "APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL",
-		Condition: func(deployment *appsv1.Deployment) bool {
-			_, found := deployment.Spec.Template.Labels["project"]
-			return found
-		},
+	APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL = NewAppsV1DeploymentRequiredLabelsRule(RequiredLabelsOptions{
+		ID:      "APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL",
+		Labels:  []string{"project"},
 		Message: "There should be a project label present under the deployment's spec.template.labels",
-		Level:   log.ErrorLevel,
-	}
+	})
 	// An AppsV1Deployment should have an app.kubernetes.io/name label.
-	APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL = &AppsV1DeploymentRule{
-		ID: "APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL",
-		Condition: func(deployment *appsv1.Deployment) bool {
-			_, found := deployment.Spec.Template.Labels["app.kubernetes.io/name"]
-			return found
-		},
+	APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL = NewAppsV1DeploymentRequiredLabelsRule(RequiredLabelsOptions{
+		ID:      "APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL",
+		Labels:  []string{"app.kubernetes.io/name"},
+		Renames: map[string]string{"app.kubernetes.io/name": "app"},
 		Message: "There should be an app.kubernetes.io/name label present for the deployment's spec.template",
-		Level:   log.ErrorLevel,
-		Fix: func(deployment *appsv1.Deployment) bool {
-			label, found := deployment.Spec.Template.Labels["app"]
-			if found {
-				delete(deployment.Spec.Template.Labels, "app")
-				deployment.Spec.Template.Labels["app.kubernetes.io/name"] = label
-				return true
-			}
-			return false
-		},
-		FixDescription: func(deployment *appsv1.Deployment) string {
-			return fmt.Sprintf("Found app label in deployment %s and used this value to populate the \"app.kubernetes.io/name\" key", deployment.Name)
-		},
-	}
+	})
 	// An AppsV1Deployment should be within a namespace
 	APPSV1_DEPLOYMENT_WITHIN_NAMESPACE = &AppsV1DeploymentRule{
 		ID: "APPSV1_DEPLOYMENT_WITHIN_NAMESPACE",
@@ -131,24 +290,32 @@ var (
 		Message: "The resource must be within a namespace",
 		Level:   log.ErrorLevel,
 	}
-	// An AppsV1Deployment should specify a liveness endpoint
+	// An AppsV1Deployment should specify a liveness endpoint for every container
 	APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS = &AppsV1DeploymentRule{
 		ID:      "APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS",
 		Prereqs: []RuleID{"V1_PODSPEC_NON_ZERO_CONTAINERS"},
 		Condition: func(deployment *appsv1.Deployment) bool {
-			return deployment.Spec.Template.Spec.Containers[0].LivenessProbe != nil &&
-				deployment.Spec.Template.Spec.Containers[0].LivenessProbe.Handler.HTTPGet != nil
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				if !hasProbeHandler(container.LivenessProbe) {
+					return false
+				}
+			}
+			return true
 		},
 		Message: "Expected declaration of liveness probe for the container (livenessProbe)",
 		Level:   log.ErrorLevel,
 	}
-	// An AppsV1Deployment should specify a readiness endpoint
+	// An AppsV1Deployment should specify a readiness endpoint for every container
 	APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS = &AppsV1DeploymentRule{
 		ID:      "APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS",
 		Prereqs: []RuleID{"V1_PODSPEC_NON_ZERO_CONTAINERS"},
 		Condition: func(deployment *appsv1.Deployment) bool {
-			return deployment.Spec.Template.Spec.Containers[0].ReadinessProbe != nil &&
-				deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.Handler.HTTPGet != nil
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				if !hasProbeHandler(container.ReadinessProbe) {
+					return false
+				}
+			}
+			return true
 		},
 		Message: "Expected declaration of readiness probe for the container (readinessProbe)",
 		Level:   log.ErrorLevel,
@@ -158,8 +325,12 @@ var (
 		ID:      "APPSV1_DEPLOYMENT_LIVENESS_READINESS_NONMATCHING",
 		Prereqs: []RuleID{"V1_PODSPEC_NON_ZERO_CONTAINERS", "APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS", "APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS"},
 		Condition: func(deployment *appsv1.Deployment) bool {
-			container := deployment.Spec.Template.Spec.Containers[0]
-			return container.LivenessProbe.Handler.HTTPGet.Path != container.ReadinessProbe.Handler.HTTPGet.Path
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				if sameProbeHandler(container.LivenessProbe, container.ReadinessProbe) {
+					return false
+				}
+			}
+			return true
 		},
 		Message: "It's recommended that the readiness and liveness probe endpoints don't match",
 		Level:   log.WarnLevel,
@@ -200,31 +371,7 @@ var (
 		},
 	}
 	// A V1PodSpec should have a user and group ID of 44444
-	V1_PODSPEC_CORRECT_USER_GROUP_ID = &V1PodSpecRule{
-		ID:      "V1_PODSPEC_CORRECT_USER_GROUP_ID",
-		Prereqs: []RuleID{"V1_PODSPEC_NON_NIL_SECURITY_CONTEXT"},
-		Condition: func(podSpec *v1.PodSpec) bool {
-			return podSpec.SecurityContext.RunAsUser != nil &&
-				podSpec.SecurityContext.RunAsGroup != nil &&
-				*podSpec.SecurityContext.RunAsUser == 44444 &&
-				*podSpec.SecurityContext.RunAsGroup == 44444
-		},
-		Message: "The user and group ID of the podspec should be set to 44444",
-		Fix: func(podSpec *v1.PodSpec) bool {
-			userId := int64(44444)
-			groupId := int64(44444)
-			if podSpec.SecurityContext == nil {
-				podSpec.SecurityContext = &corev1.PodSecurityContext{}
-			}
-			podSpec.SecurityContext.RunAsUser = &userId
-			podSpec.SecurityContext.RunAsGroup = &groupId
-			return true
-		},
-		Level: log.ErrorLevel,
-		FixDescription: func(podSpec *v1.PodSpec) string {
-			return "Set pod's User and Group ID to 44444"
-		},
-	}
+	V1_PODSPEC_CORRECT_USER_GROUP_ID = NewV1PodSpecUserGroupIDRule(DefaultUserGroupIDOptions)
 	// A V1PodSpec should have exactly one container
 	V1_PODSPEC_EXACTLY_1_CONTAINER = &V1PodSpecRule{
 		ID: "V1_PODSPEC_EXACTLY_1_CONTAINER",
@@ -278,14 +425,42 @@ var (
 			return fmt.Sprintf("Set AllowPrivilegeEscalation to false on Container %s", container.Name)
 		},
 	}
-	// A V1Container's image should come from a set of allowed images defined in isImageAllowed
-	V1_CONTAINER_VALID_IMAGE = &V1ContainerRule{
-		ID: "V1_CONTAINER_VALID_IMAGE",
+	// A V1Container's image should come from an allowed registry (see DefaultImageOptions)
+	V1_CONTAINER_VALID_IMAGE = NewV1ContainerValidImageRule(DefaultImageOptions)
+	// A V1Container's image should have a tag other than latest, or a digest
+	V1_CONTAINER_IMAGE_NOT_LATEST = NewV1ContainerImageNotLatestRule(nil)
+	// A V1Container's image should be pinned to a digest
+	V1_CONTAINER_IMAGE_DIGEST_PINNED = NewV1ContainerImageDigestRule(nil)
+	// A V1Container's imagePullPolicy should suit whether its image can change
+	V1_CONTAINER_IMAGE_PULL_POLICY_CONSISTENT = &V1ContainerRule{
+		ID: "V1_CONTAINER_IMAGE_PULL_POLICY_CONSISTENT",
 		Condition: func(container *v1.Container) bool {
-			return isImageAllowed(container.Image)
+			reference, err := ParseImageReference(container.Image)
+			if err != nil {
+				return true
+			}
+			if reference.IsLatest() {
+				return container.ImagePullPolicy == "" || container.ImagePullPolicy == v1.PullAlways
+			}
+			return reference.IsMutable() || container.ImagePullPolicy != v1.PullAlways
+		},
+		Message: "The container's imagePullPolicy should be Always for a latest image, and shouldn't be Always for an image pinned to a digest",
+		Level:   log.WarnLevel,
+		Fix: func(container *v1.Container) bool {
+			reference, err := ParseImageReference(container.Image)
+			if err != nil {
+				return false
+			}
+			if reference.IsLatest() {
+				container.ImagePullPolicy = v1.PullAlways
+			} else {
+				container.ImagePullPolicy = v1.PullIfNotPresent
+			}
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return fmt.Sprintf("Set container %s's imagePullPolicy to %s", container.Name, container.ImagePullPolicy)
 		},
-		Message: "The container's image was not from the set of allowed images",
-		Level:   log.ErrorLevel,
 	}
 	// A V1Container should have privileged set to false
 	V1_CONTAINER_PRIVILEGED_FALSE = &V1ContainerRule{
@@ -316,17 +491,93 @@ var (
 		Level:   log.ErrorLevel,
 	}
 	// A V1Container should make CPU requests that are less than or equal to 100%
-	V1_CONTAINER_REQUESTS_CPU_REASONABLE = &V1ContainerRule{
-		ID:      "V1_CONTAINER_REQUESTS_CPU_REASONABLE",
-		Prereqs: []RuleID{"V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS"},
+	V1_CONTAINER_REQUESTS_CPU_REASONABLE = NewV1ContainerCPURule(CPUOptions{
+		MaxRequest: DefaultCPUOptions.MaxRequest,
+		MaxLimit:   DefaultCPUOptions.MaxLimit,
+		Message:    "You should request less than 1 unit of CPU",
+	})
+	// A V1Container's memory limit should be the same as its request, so the pod can be Guaranteed and won't be killed first when the node runs out of memory
+	V1_CONTAINER_MEMORY_LIMIT_EQUALS_REQUEST = &V1ContainerRule{
+		ID: "V1_CONTAINER_MEMORY_LIMIT_EQUALS_REQUEST",
+		Condition: func(container *v1.Container) bool {
+			limit, found := container.Resources.Limits[v1.ResourceMemory]
+			if !found {
+				return false
+			}
+			request := containerRequests(container)[v1.ResourceMemory]
+			return request.Cmp(limit) == 0
+		},
+		Message: "The container's memory limit should be set, and its memory request should be the same",
+		Level:   log.ErrorLevel,
+		Fix: func(container *v1.Container) bool {
+			limit, found := container.Resources.Limits[v1.ResourceMemory]
+			if !found {
+				return false
+			}
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = make(v1.ResourceList)
+			}
+			container.Resources.Requests[v1.ResourceMemory] = limit.DeepCopy()
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return fmt.Sprintf("Set container %s's memory request to its limit", container.Name)
+		},
+	}
+	// A V1Container should have a liveness probe
+	V1_CONTAINER_EXISTS_LIVENESS = &V1ContainerRule{
+		ID: "V1_CONTAINER_EXISTS_LIVENESS",
+		Condition: func(container *v1.Container) bool {
+			return hasProbeHandler(container.LivenessProbe)
+		},
+		Message: "Expected declaration of liveness probe for the container (livenessProbe) with an httpGet, tcpSocket or exec handler",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container should have a readiness probe
+	V1_CONTAINER_EXISTS_READINESS = &V1ContainerRule{
+		ID: "V1_CONTAINER_EXISTS_READINESS",
+		Condition: func(container *v1.Container) bool {
+			return hasProbeHandler(container.ReadinessProbe)
+		},
+		Message: "Expected declaration of readiness probe for the container (readinessProbe) with an httpGet, tcpSocket or exec handler",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container's liveness and readiness probes shouldn't check the same thing
+	V1_CONTAINER_LIVENESS_READINESS_NONMATCHING = &V1ContainerRule{
+		ID:      "V1_CONTAINER_LIVENESS_READINESS_NONMATCHING",
+		Prereqs: []RuleID{"V1_CONTAINER_EXISTS_LIVENESS", "V1_CONTAINER_EXISTS_READINESS"},
+		Condition: func(container *v1.Container) bool {
+			return !sameProbeHandler(container.LivenessProbe, container.ReadinessProbe)
+		},
+		Message: "It's recommended that the readiness and liveness probes don't check the same thing, so a container that's busy isn't restarted",
+		Level:   log.WarnLevel,
+	}
+	// A V1Container's probes should connect to one of its ports
+	V1_CONTAINER_PROBE_PORTS_EXIST = &V1ContainerRule{
+		ID: "V1_CONTAINER_PROBE_PORTS_EXIST",
 		Condition: func(container *v1.Container) bool {
-			// If the container is requesting CPU, it shouldn't be more than 1 unit.
-			cpuUsage := container.Resources.Requests.Cpu()
-			return cpuUsage.CmpInt64(1) != 1
+			for _, probe := range containerProbes(container) {
+				if port, ok := probePort(probe.probe); ok && !containerHasPort(container, port) {
+					return false
+				}
+			}
+			return true
 		},
-		Message: "You should request less than 1 unit of CPU",
+		Message: "The container's probes should connect to ports in its ports list (containerPort or name)",
 		Level:   log.ErrorLevel,
 	}
+	// A V1Container that takes a long time to start should have a startup probe
+	V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS = NewV1ContainerStartupProbeRule(DefaultStartupProbeOptions)
+	// A V1Container's probes should have sensible timings
+	V1_CONTAINER_PROBE_TIMINGS = NewV1ContainerProbeTimingsRule(DefaultProbeTimingsOptions)
+	// A V1Container's limits shouldn't be too far above its requests
+	V1_CONTAINER_LIMIT_REQUEST_RATIO = NewV1ContainerLimitRequestRatioRule(DefaultLimitRequestRatioOptions)
+	// A V1Container should have an ephemeral-storage limit
+	V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT = NewV1ContainerEphemeralStorageRule(EphemeralStorageOptions{})
+	// A V1PodSpec shouldn't request (or be limited to) too much in total
+	V1_PODSPEC_RESOURCE_TOTALS = NewV1PodSpecResourceTotalsRule(DefaultPodResourceTotalsOptions)
+	// A V1PodSpec shouldn't be BestEffort
+	V1_PODSPEC_QOS_CLASS = NewV1PodSpecQOSClassRule(DefaultQOSClassOptions)
 	// A BatchV1Beta1CronJob should be within a namespace
 	BATCHV1_BETA1_CRONJOB_WITHIN_NAMESPACE = &BatchV1Beta1CronJobRule{
 		ID: "BATCHV1_BETA1_CRONJOB_WITHIN_NAMESPACE",
@@ -421,6 +672,251 @@ var (
 		Level:   log.ErrorLevel,
 		Message: "A service's name needs to be a valid DNS",
 	}
+	// A V1ConfigMap's data shouldn't contain AWS access keys, private keys or random looking tokens
+	V1_CONFIGMAP_NO_CREDENTIALS = &V1ConfigMapRule{
+		ID: "V1_CONFIGMAP_NO_CREDENTIALS",
+		Condition: func(configmap *v1.ConfigMap) bool {
+			return !configMapHasCredentials(configmap)
+		},
+		Level:   log.ErrorLevel,
+		Message: "A config map's data shouldn't contain credentials (AWS access keys, private keys or random looking tokens), put them in a secret",
+	}
+	// A V1ConfigMap's data shouldn't be within 10% of the 1MiB limit
+	V1_CONFIGMAP_SIZE_UNDER_LIMIT = &V1ConfigMapRule{
+		ID: "V1_CONFIGMAP_SIZE_UNDER_LIMIT",
+		Condition: func(configmap *v1.ConfigMap) bool {
+			return configMapSize(configmap) <= dataSizeWarning
+		},
+		Level:   log.WarnLevel,
+		Message: "A config map's data is close to the 1MiB limit, past which the API server will reject it",
+	}
+	// A V1ConfigMap's keys should be valid, and not be in both data and binaryData
+	V1_CONFIGMAP_VALID_KEYS = &V1ConfigMapRule{
+		ID: "V1_CONFIGMAP_VALID_KEYS",
+		Condition: func(configmap *v1.ConfigMap) bool {
+			return validDataKeys(configMapKeys(configmap))
+		},
+		Level:   log.ErrorLevel,
+		Message: "A config map's keys should only contain alphanumeric characters, '-', '_' or '.', and not be in both data and binaryData",
+	}
+	// A V1Secret shouldn't be committed with plaintext stringData
+	V1_SECRET_NO_STRING_DATA = &V1SecretRule{
+		ID: "V1_SECRET_NO_STRING_DATA",
+		Condition: func(secret *v1.Secret) bool {
+			return len(secret.StringData) == 0
+		},
+		Level:   log.ErrorLevel,
+		Message: "A secret shouldn't be committed with plaintext stringData, use a sealed or externally managed secret instead",
+	}
+	// A V1Secret's data shouldn't be within 10% of the 1MiB limit
+	V1_SECRET_SIZE_UNDER_LIMIT = &V1SecretRule{
+		ID: "V1_SECRET_SIZE_UNDER_LIMIT",
+		Condition: func(secret *v1.Secret) bool {
+			return secretSize(secret) <= dataSizeWarning
+		},
+		Level:   log.WarnLevel,
+		Message: "A secret's data is close to the 1MiB limit, past which the API server will reject it",
+	}
+	// A V1Secret's keys should be valid
+	V1_SECRET_VALID_KEYS = &V1SecretRule{
+		ID: "V1_SECRET_VALID_KEYS",
+		Condition: func(secret *v1.Secret) bool {
+			return validDataKeys(secretKeys(secret))
+		},
+		Level:   log.ErrorLevel,
+		Message: "A secret's keys should only contain alphanumeric characters, '-', '_' or '.'",
+	}
+	// A RbacV1Role shouldn't use "*" for verbs or resources
+	RBACV1_ROLE_NO_WILDCARDS = &RbacV1RoleRule{
+		ID: "RBACV1_ROLE_NO_WILDCARDS",
+		Condition: func(role *rbacV1.Role) bool {
+			return !anyRule(role.Rules, hasWildcard)
+		},
+		Level:   log.ErrorLevel,
+		Message: "A role shouldn't use \"*\" for verbs or resources, since it grants whatever gets added to the API later",
+	}
+	// A RbacV1Role shouldn't allow escalate, bind or impersonate
+	RBACV1_ROLE_NO_PRIVILEGE_ESCALATION = &RbacV1RoleRule{
+		ID: "RBACV1_ROLE_NO_PRIVILEGE_ESCALATION",
+		Condition: func(role *rbacV1.Role) bool {
+			return !anyRule(role.Rules, allowsAny(privilegeEscalationAccess))
+		},
+		Level:   log.ErrorLevel,
+		Message: "A role shouldn't allow escalate or bind on roles, or impersonate, since they let you grant yourself more permissions",
+	}
+	// A RbacV1Role shouldn't allow reading secrets
+	RBACV1_ROLE_NO_SECRETS_READ = &RbacV1RoleRule{
+		ID: "RBACV1_ROLE_NO_SECRETS_READ",
+		Condition: func(role *rbacV1.Role) bool {
+			return !anyRule(role.Rules, allowsAny(secretsReadAccess))
+		},
+		Level:   log.WarnLevel,
+		Message: "A role shouldn't allow get, list or watch on secrets unless it really has to, since that exposes every credential in its scope",
+	}
+	// A RbacV1Role shouldn't allow pods/exec
+	RBACV1_ROLE_NO_POD_EXEC = &RbacV1RoleRule{
+		ID: "RBACV1_ROLE_NO_POD_EXEC",
+		Condition: func(role *rbacV1.Role) bool {
+			return !anyRule(role.Rules, allowsAny(podExecAccess))
+		},
+		Level:   log.WarnLevel,
+		Message: "A role shouldn't allow pods/exec, since it runs commands inside containers with their credentials",
+	}
+	// A RbacV1ClusterRole shouldn't use "*" for verbs or resources
+	RBACV1_CLUSTERROLE_NO_WILDCARDS = &RbacV1ClusterRoleRule{
+		ID: "RBACV1_CLUSTERROLE_NO_WILDCARDS",
+		Condition: func(role *rbacV1.ClusterRole) bool {
+			return !anyRule(role.Rules, hasWildcard)
+		},
+		Level:   log.ErrorLevel,
+		Message: "A cluster role shouldn't use \"*\" for verbs or resources, since it grants whatever gets added to the API later",
+	}
+	// A RbacV1ClusterRole shouldn't allow escalate, bind or impersonate
+	RBACV1_CLUSTERROLE_NO_PRIVILEGE_ESCALATION = &RbacV1ClusterRoleRule{
+		ID: "RBACV1_CLUSTERROLE_NO_PRIVILEGE_ESCALATION",
+		Condition: func(role *rbacV1.ClusterRole) bool {
+			return !anyRule(role.Rules, allowsAny(privilegeEscalationAccess))
+		},
+		Level:   log.ErrorLevel,
+		Message: "A cluster role shouldn't allow escalate or bind on roles, or impersonate, since they let you grant yourself more permissions",
+	}
+	// A RbacV1ClusterRole shouldn't allow reading secrets
+	RBACV1_CLUSTERROLE_NO_SECRETS_READ = &RbacV1ClusterRoleRule{
+		ID: "RBACV1_CLUSTERROLE_NO_SECRETS_READ",
+		Condition: func(role *rbacV1.ClusterRole) bool {
+			return !anyRule(role.Rules, allowsAny(secretsReadAccess))
+		},
+		Level:   log.WarnLevel,
+		Message: "A cluster role shouldn't allow get, list or watch on secrets unless it really has to, since that exposes every credential in its scope",
+	}
+	// A RbacV1ClusterRole shouldn't allow pods/exec
+	RBACV1_CLUSTERROLE_NO_POD_EXEC = &RbacV1ClusterRoleRule{
+		ID: "RBACV1_CLUSTERROLE_NO_POD_EXEC",
+		Condition: func(role *rbacV1.ClusterRole) bool {
+			return !anyRule(role.Rules, allowsAny(podExecAccess))
+		},
+		Level:   log.WarnLevel,
+		Message: "A cluster role shouldn't allow pods/exec, since it runs commands inside containers with their credentials",
+	}
+	// A RbacV1RoleBinding shouldn't grant cluster-admin
+	RBACV1_ROLEBINDING_NOT_CLUSTER_ADMIN = &RbacV1RoleBindingRule{
+		ID: "RBACV1_ROLEBINDING_NOT_CLUSTER_ADMIN",
+		Condition: func(binding *rbacV1.RoleBinding) bool {
+			return binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != "cluster-admin"
+		},
+		Level:   log.ErrorLevel,
+		Message: "A role binding shouldn't grant cluster-admin, which allows everything",
+	}
+	// A RbacV1Beta1RoleBinding shouldn't grant cluster-admin
+	RBACV1BETA1_ROLEBINDING_NOT_CLUSTER_ADMIN = &RbacV1Beta1RoleBindingRule{
+		ID: "RBACV1BETA1_ROLEBINDING_NOT_CLUSTER_ADMIN",
+		Condition: func(binding *rbacV1beta1.RoleBinding) bool {
+			return binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != "cluster-admin"
+		},
+		Level:   log.ErrorLevel,
+		Message: "A role binding shouldn't grant cluster-admin, which allows everything",
+	}
+	// A RbacV1ClusterRoleBinding shouldn't grant cluster-admin
+	RBACV1_CLUSTERROLEBINDING_NOT_CLUSTER_ADMIN = &RbacV1ClusterRoleBindingRule{
+		ID: "RBACV1_CLUSTERROLEBINDING_NOT_CLUSTER_ADMIN",
+		Condition: func(binding *rbacV1.ClusterRoleBinding) bool {
+			return binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != "cluster-admin"
+		},
+		Level:   log.ErrorLevel,
+		Message: "A cluster role binding shouldn't grant cluster-admin, which allows everything",
+	}
+	// A V1Beta1ExtensionsIngress should serve every host over TLS
+	V1BETA1_EXTENSIONS_INGRESS_TLS_COVERS_HOSTS = &V1Beta1ExtensionsIngressRule{
+		ID: "V1BETA1_EXTENSIONS_INGRESS_TLS_COVERS_HOSTS",
+		Condition: func(ingress *v1beta1Extensions.Ingress) bool {
+			spec, ok := ingressSpec(ingress)
+			return ok && tlsCoversHosts(spec)
+		},
+		Level:   log.ErrorLevel,
+		Message: "An ingress should serve every host in its rules over TLS",
+	}
+	// A V1Beta1ExtensionsIngress shouldn't have rules for wildcard hosts
+	V1BETA1_EXTENSIONS_INGRESS_NO_WILDCARD_HOSTS = &V1Beta1ExtensionsIngressRule{
+		ID: "V1BETA1_EXTENSIONS_INGRESS_NO_WILDCARD_HOSTS",
+		Condition: func(ingress *v1beta1Extensions.Ingress) bool {
+			spec, ok := ingressSpec(ingress)
+			return ok && !hasWildcardHost(spec)
+		},
+		Level:   log.WarnLevel,
+		Message: "An ingress shouldn't have rules for wildcard hosts, since it takes traffic for subdomains nobody has claimed",
+	}
+	// A V1Beta1ExtensionsIngress should have a kubernetes.io/ingress.class annotation
+	V1BETA1_EXTENSIONS_INGRESS_EXISTS_CLASS_ANNOTATION = &V1Beta1ExtensionsIngressRule{
+		ID: "V1BETA1_EXTENSIONS_INGRESS_EXISTS_CLASS_ANNOTATION",
+		Condition: func(ingress *v1beta1Extensions.Ingress) bool {
+			return hasIngressClass(ingress)
+		},
+		Level:   log.ErrorLevel,
+		Message: "An ingress should have a kubernetes.io/ingress.class annotation, otherwise every ingress controller in the cluster may serve it",
+	}
+	// A NetworkingV1beta1Ingress should serve every host over TLS
+	NETWORKINGV1BETA1_INGRESS_TLS_COVERS_HOSTS = &NetworkingV1beta1IngressRule{
+		ID: "NETWORKINGV1BETA1_INGRESS_TLS_COVERS_HOSTS",
+		Condition: func(ingress *networkingV1beta1.Ingress) bool {
+			spec, ok := ingressSpec(ingress)
+			return ok && tlsCoversHosts(spec)
+		},
+		Level:   log.ErrorLevel,
+		Message: "An ingress should serve every host in its rules over TLS",
+	}
+	// A NetworkingV1beta1Ingress shouldn't have rules for wildcard hosts
+	NETWORKINGV1BETA1_INGRESS_NO_WILDCARD_HOSTS = &NetworkingV1beta1IngressRule{
+		ID: "NETWORKINGV1BETA1_INGRESS_NO_WILDCARD_HOSTS",
+		Condition: func(ingress *networkingV1beta1.Ingress) bool {
+			spec, ok := ingressSpec(ingress)
+			return ok && !hasWildcardHost(spec)
+		},
+		Level:   log.WarnLevel,
+		Message: "An ingress shouldn't have rules for wildcard hosts, since it takes traffic for subdomains nobody has claimed",
+	}
+	// A NetworkingV1beta1Ingress should have a kubernetes.io/ingress.class annotation
+	NETWORKINGV1BETA1_INGRESS_EXISTS_CLASS_ANNOTATION = &NetworkingV1beta1IngressRule{
+		ID: "NETWORKINGV1BETA1_INGRESS_EXISTS_CLASS_ANNOTATION",
+		Condition: func(ingress *networkingV1beta1.Ingress) bool {
+			return hasIngressClass(ingress)
+		},
+		Level:   log.ErrorLevel,
+		Message: "An ingress should have a kubernetes.io/ingress.class annotation, otherwise every ingress controller in the cluster may serve it",
+	}
+	// An AutoscalingV1HorizontalPodAutoscaler's minReplicas shouldn't be more than its maxReplicas
+	AUTOSCALINGV1_HPA_MIN_REPLICAS_AT_MOST_MAX = &AutoscalingV1HorizontalPodAutoscalerRule{
+		ID: "AUTOSCALINGV1_HPA_MIN_REPLICAS_AT_MOST_MAX",
+		Condition: func(autoscaler *autoscalingV1.HorizontalPodAutoscaler) bool {
+			minReplicas, maxReplicas, _ := autoscalerReplicas(autoscaler)
+			return minReplicas <= maxReplicas
+		},
+		Level:   log.ErrorLevel,
+		Message: "A horizontal pod autoscaler's minReplicas (1 if it's left out) can't be more than its maxReplicas",
+	}
+	// An AutoscalingV2beta2HorizontalPodAutoscaler's minReplicas shouldn't be more than its maxReplicas
+	AUTOSCALINGV2BETA2_HPA_MIN_REPLICAS_AT_MOST_MAX = &AutoscalingV2beta2HorizontalPodAutoscalerRule{
+		ID: "AUTOSCALINGV2BETA2_HPA_MIN_REPLICAS_AT_MOST_MAX",
+		Condition: func(autoscaler *autoscalingV2beta2.HorizontalPodAutoscaler) bool {
+			minReplicas, maxReplicas, _ := autoscalerReplicas(autoscaler)
+			return minReplicas <= maxReplicas
+		},
+		Level:   log.ErrorLevel,
+		Message: "A horizontal pod autoscaler's minReplicas (1 if it's left out) can't be more than its maxReplicas",
+	}
+	// A PolicyV1beta1PodDisruptionBudget shouldn't set maxUnavailable to 0 or minAvailable to 100%
+	POLICYV1BETA1_PDB_ALLOWS_EVICTION = &PolicyV1beta1PodDisruptionBudgetRule{
+		ID: "POLICYV1BETA1_PDB_ALLOWS_EVICTION",
+		Condition: func(budget *policyV1beta1.PodDisruptionBudget) bool {
+			if budget.Spec.MaxUnavailable == nil && (budget.Spec.MinAvailable == nil || budget.Spec.MinAvailable.Type == intstr.Int) {
+				return true // whether a number of pods is too many depends on the workload, see INTERDEPENDENT_PDB_ALLOWS_EVICTION
+			}
+			// maxUnavailable and percentages don't depend on how many pods there are, so any number of pods will do
+			allowed, err := disruptionsAllowed(budget, 100)
+			return err == nil && allowed > 0
+		},
+		Level:   log.ErrorLevel,
+		Message: "A pod disruption budget shouldn't set maxUnavailable to 0 or minAvailable to 100%, since then its pods can never be evicted (eg to drain a node)",
+	}
 )
 
 var (
@@ -442,55 +938,40 @@ var (
 	// All resources should be under the namespace in the unit
 	INTERDEPENDENT_MATCHING_NAMESPACE = &InterdependentRule{
 		ID: "INTERDEPENDENT_MATCHING_NAMESPACE",
-		Condition: func(resources []*Resource) (bool, []*Resource) {
-			var namespace *v1.Namespace
-			for _, resource := range resources {
-				if ns, ok := resource.Object.(*v1.Namespace); ok {
-					namespace = ns
-					break
-				}
-			}
-			if namespace == nil {
-				return true, nil
+		Violations: func(graph *ResourceGraph) []*Violation {
+			namespaces := graph.Kind("Namespace")
+			if len(namespaces) == 0 {
+				return nil
 			}
-			var wrongNamespaceResources []*Resource
+			namespace := namespaces[0].Object.GetName()
+			var violations []*Violation
 			// now test that all ppl are under that namespace
-			for _, resource := range resources {
-				if resource.TypeInfo.GetKind() == "Namespace" {
+			for _, resource := range graph.Resources() {
+				// cluster scoped resources (like ClusterRoles) can't be given a namespace
+				if !graph.IsNamespaced(resource) || resource.Object.GetNamespace() == namespace {
 					continue
 				}
-				if resource.Object.GetNamespace() != namespace.Name {
-					wrongNamespaceResources = append(wrongNamespaceResources, resource)
+				resource := resource
+				message := fmt.Sprintf("%s is in namespace %s but the unit's namespace is %s", kindAndName(resource), resource.Object.GetNamespace(), namespace)
+				if resource.Object.GetNamespace() == "" {
+					message = fmt.Sprintf("%s has no namespace but the unit's namespace is %s", kindAndName(resource), namespace)
 				}
+				violations = append(violations, &Violation{
+					Resources: []*Resource{resource},
+					Message:   message,
+					Fix: func() bool {
+						resource.Object.SetNamespace(namespace)
+						return true
+					},
+					FixDescription: func() string {
+						return fmt.Sprintf("Moved %s into namespace %s", kindAndName(resource), namespace)
+					},
+				})
 			}
-			return len(wrongNamespaceResources) == 0, wrongNamespaceResources
+			return violations
 		},
 		Message: "All resources must be under the correct namespace",
 		Level:   log.ErrorLevel,
-		Fix: func(resources []*Resource) bool {
-			for _, resource := range resources {
-				if ns, ok := resource.Object.(*v1.Namespace); ok {
-					name := ns.Name
-					for _, r := range resources {
-						if r.Object == ns {
-							continue
-						}
-						r.Object.SetNamespace(name)
-					}
-					break
-				}
-			}
-			return true
-		},
-		FixDescription: func(resources []*Resource) string {
-			var namespace string
-			for _, resource := range resources {
-				if resource.Object.GetNamespace() != "" {
-					namespace = resource.Object.GetNamespace()
-				}
-			}
-			return fmt.Sprintf("Set everyone's namespace to %s", namespace)
-		},
 	}
 	// There should be a network policy for the namespace
 	INTERDEPENDENT_NETWORK_POLICY_REQUIRED = &InterdependentRule{
@@ -519,13 +1000,3 @@ var (
 	}
 )
 
-func isImageAllowed(image string) bool {
-	ALLOWED_DOCKER_REGISTRIES := []string{"277433404353.dkr.ecr.eu-central-1.amazonaws.com"}
-	for _, r := range ALLOWED_DOCKER_REGISTRIES {
-		if strings.HasPrefix(image, r) {
-			return true
-		}
-	}
-	return false
-
-}
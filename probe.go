@@ -0,0 +1,76 @@
+package kubelint
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The values Kubernetes uses for a probe's settings when they're left out.
+const (
+	defaultProbePeriodSeconds    = 10
+	defaultProbeTimeoutSeconds   = 1
+	defaultProbeFailureThreshold = 3
+)
+
+// namedProbe is one of a container's probes, with the field it's in.
+type namedProbe struct {
+	field string // livenessProbe, readinessProbe or startupProbe
+	probe *v1.Probe
+}
+
+// containerProbes gives you the probes the container has set.
+func containerProbes(container *v1.Container) []namedProbe {
+	var probes []namedProbe
+	for _, probe := range []namedProbe{
+		{"livenessProbe", container.LivenessProbe},
+		{"readinessProbe", container.ReadinessProbe},
+		{"startupProbe", container.StartupProbe},
+	} {
+		if probe.probe != nil {
+			probes = append(probes, probe)
+		}
+	}
+	return probes
+}
+
+// hasProbeHandler tells you if the probe is set, and says how to check the container
+// (with an HTTP request, a TCP connection or a command).
+func hasProbeHandler(probe *v1.Probe) bool {
+	return probe != nil && (probe.HTTPGet != nil || probe.TCPSocket != nil || probe.Exec != nil)
+}
+
+// sameProbeHandler tells you if two probes check the container the same way, eg by requesting the same path on the same port.
+func sameProbeHandler(a *v1.Probe, b *v1.Probe) bool {
+	return equality.Semantic.DeepEqual(a.Handler, b.Handler)
+}
+
+// probePort gives you the port an HTTP or TCP probe connects to, and false for an exec probe.
+func probePort(probe *v1.Probe) (intstr.IntOrString, bool) {
+	switch {
+	case probe.HTTPGet != nil:
+		return probe.HTTPGet.Port, true
+	case probe.TCPSocket != nil:
+		return probe.TCPSocket.Port, true
+	}
+	return intstr.IntOrString{}, false
+}
+
+// containerHasPort tells you if the port (a number or a name) is one of the container's ports.
+func containerHasPort(container *v1.Container, port intstr.IntOrString) bool {
+	for _, containerPort := range container.Ports {
+		if (port.Type == intstr.String && containerPort.Name == port.StrVal) ||
+			(port.Type == intstr.Int && containerPort.ContainerPort == port.IntVal) {
+			return true
+		}
+	}
+	return false
+}
+
+// orDefault gives you the value of a probe setting, or what Kubernetes will use if it's left out.
+func orDefault(value int32, defaultValue int32) int32 {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
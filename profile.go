@@ -0,0 +1,499 @@
+package kubelint
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Profile is a bundle of rules that implement one of the Pod Security Standards
+// (https://kubernetes.io/docs/concepts/security/pod-security-standards/).
+// Add one to a linter with linter.AddProfile(kubelint.ProfileRestricted), and every result of its rules
+// will say which control it failed in Result.Control.
+type Profile struct {
+	Name           string
+	GenericRules   []*GenericRule     // the controls that are set in the pod's annotations, like AppArmor
+	PodSpecRules   []*V1PodSpecRule   // the controls that are set for the whole pod
+	ContainerRules []*V1ContainerRule // the controls that are set for each container (init containers included)
+	Controls       map[RuleID]string  // the name of the control each rule checks, eg "Host Namespaces"
+}
+
+// The annotations that stand in for the seccompProfile and appArmorProfile fields, which this version of the API doesn't have.
+const (
+	seccompPodAnnotation             = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	appArmorAnnotationPrefix         = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// baselineCapabilities are the capabilities the Baseline standard lets a container add.
+var baselineCapabilities = map[v1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// safeSysctls are the sysctls the Baseline standard allows, since they're namespaced and can't affect other pods.
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.ping_group_range":           true,
+}
+
+// seLinuxTypes are the SELinux types the Baseline standard allows (as well as leaving it unset).
+var seLinuxTypes = map[string]bool{
+	"":                 true,
+	"container_t":      true,
+	"container_init_t": true,
+	"container_kvm_t":  true,
+}
+
+// podAnnotations gives you the annotations of the pods the resource creates, and false if it doesn't create any.
+func podAnnotations(resource *Resource) (map[string]string, bool) {
+	metadata, _, ok := podTemplate(resource.Object)
+	if !ok {
+		return nil, false
+	}
+	return metadata.Annotations, true
+}
+
+// isSeccompProfileConfined tells you if a seccomp annotation names the runtime's default profile or one on the node.
+func isSeccompProfileConfined(profile string) bool {
+	return profile == "runtime/default" || profile == "docker/default" || strings.HasPrefix(profile, "localhost/")
+}
+
+// isSELinuxOptionAllowed tells you if the SELinux options (which may be nil) only set an allowed type and level.
+func isSELinuxOptionAllowed(options *v1.SELinuxOptions) bool {
+	return options == nil || (seLinuxTypes[options.Type] && options.User == "" && options.Role == "")
+}
+
+// containersOf gives you every container in the pod, init containers first.
+func containersOf(podSpec *v1.PodSpec) []v1.Container {
+	return append(append([]v1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+}
+
+var (
+	// A pod shouldn't share the host's network, process or IPC namespaces
+	V1_PODSPEC_PSS_HOST_NAMESPACES = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_HOST_NAMESPACES",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			return !podSpec.HostNetwork && !podSpec.HostPID && !podSpec.HostIPC
+		},
+		Message: "The pod shouldn't use the host's network, PID or IPC namespace (hostNetwork, hostPID and hostIPC should be false)",
+		Level:   log.ErrorLevel,
+		Fix: func(podSpec *v1.PodSpec) bool {
+			podSpec.HostNetwork = false
+			podSpec.HostPID = false
+			podSpec.HostIPC = false
+			return true
+		},
+		FixDescription: func(podSpec *v1.PodSpec) string {
+			return "Set the pod's hostNetwork, hostPID and hostIPC to false"
+		},
+	}
+	// A pod shouldn't mount hostPath volumes
+	V1_PODSPEC_PSS_HOST_PATH_VOLUMES = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_HOST_PATH_VOLUMES",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			for _, volume := range podSpec.Volumes {
+				if volume.HostPath != nil {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod shouldn't mount hostPath volumes",
+		Level:   log.ErrorLevel,
+	}
+	// A pod's SELinux options should only set an allowed type
+	V1_PODSPEC_PSS_SELINUX = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_SELINUX",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			return podSpec.SecurityContext == nil || isSELinuxOptionAllowed(podSpec.SecurityContext.SELinuxOptions)
+		},
+		Message: "The pod's seLinuxOptions shouldn't set a user or role, and its type should be container_t, container_init_t or container_kvm_t",
+		Level:   log.ErrorLevel,
+	}
+	// A pod should only set sysctls that are safe
+	V1_PODSPEC_PSS_SYSCTLS = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_SYSCTLS",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			if podSpec.SecurityContext == nil {
+				return true
+			}
+			for _, sysctl := range podSpec.SecurityContext.Sysctls {
+				if !safeSysctls[sysctl.Name] {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod should only set the safe sysctls (kernel.shm_rmid_forced, net.ipv4.ip_local_port_range, net.ipv4.ip_unprivileged_port_start, net.ipv4.tcp_syncookies and net.ipv4.ping_group_range)",
+		Level:   log.ErrorLevel,
+	}
+	// A pod should only use volumes that are backed by the cluster, rather than the node
+	V1_PODSPEC_PSS_VOLUME_TYPES = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_VOLUME_TYPES",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			for _, volume := range podSpec.Volumes {
+				source := volume.VolumeSource
+				if source.ConfigMap == nil && source.CSI == nil && source.DownwardAPI == nil && source.EmptyDir == nil &&
+					source.PersistentVolumeClaim == nil && source.Projected == nil && source.Secret == nil {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod should only use configMap, csi, downwardAPI, emptyDir, persistentVolumeClaim, projected and secret volumes",
+		Level:   log.ErrorLevel,
+	}
+	// A pod's containers should all have to run as non-root users
+	V1_PODSPEC_PSS_RUN_AS_NON_ROOT = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_RUN_AS_NON_ROOT",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			podNonRoot := podSpec.SecurityContext != nil && podSpec.SecurityContext.RunAsNonRoot != nil && *podSpec.SecurityContext.RunAsNonRoot
+			for _, container := range containersOf(podSpec) {
+				if container.SecurityContext == nil || container.SecurityContext.RunAsNonRoot == nil {
+					if !podNonRoot {
+						return false
+					}
+				} else if !*container.SecurityContext.RunAsNonRoot {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "runAsNonRoot should be true for the pod, or for every container, and no container should set it to false",
+		Level:   log.ErrorLevel,
+		Fix: func(podSpec *v1.PodSpec) bool {
+			runAsNonRoot := true
+			if podSpec.SecurityContext == nil {
+				podSpec.SecurityContext = &v1.PodSecurityContext{}
+			}
+			podSpec.SecurityContext.RunAsNonRoot = &runAsNonRoot
+			for _, containers := range [][]v1.Container{podSpec.InitContainers, podSpec.Containers} {
+				for i := range containers {
+					if containers[i].SecurityContext != nil {
+						containers[i].SecurityContext.RunAsNonRoot = nil
+					}
+				}
+			}
+			return true
+		},
+		FixDescription: func(podSpec *v1.PodSpec) string {
+			return "Set the pod's runAsNonRoot to true, and let its containers inherit it"
+		},
+	}
+	// A pod's containers shouldn't be told to run as root
+	V1_PODSPEC_PSS_RUN_AS_NON_ROOT_USER = &V1PodSpecRule{
+		ID: "V1_PODSPEC_PSS_RUN_AS_NON_ROOT_USER",
+		Condition: func(podSpec *v1.PodSpec) bool {
+			if podSpec.SecurityContext != nil && podSpec.SecurityContext.RunAsUser != nil && *podSpec.SecurityContext.RunAsUser == 0 {
+				return false
+			}
+			for _, container := range containersOf(podSpec) {
+				if container.SecurityContext != nil && container.SecurityContext.RunAsUser != nil && *container.SecurityContext.RunAsUser == 0 {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "runAsUser shouldn't be 0 for the pod or any of its containers",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container shouldn't be privileged
+	V1_CONTAINER_PSS_PRIVILEGED = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_PRIVILEGED",
+		Condition: func(container *v1.Container) bool {
+			return container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged
+		},
+		Message: "The container shouldn't be privileged",
+		Level:   log.ErrorLevel,
+		Fix: func(container *v1.Container) bool {
+			if container.SecurityContext != nil {
+				container.SecurityContext.Privileged = nil
+			}
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return "Removed privileged from container " + container.Name
+		},
+	}
+	// A V1Container should only add the capabilities the Baseline standard allows
+	V1_CONTAINER_PSS_BASELINE_CAPABILITIES = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_BASELINE_CAPABILITIES",
+		Condition: func(container *v1.Container) bool {
+			if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+				return true
+			}
+			for _, capability := range container.SecurityContext.Capabilities.Add {
+				if !baselineCapabilities[capability] {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The container should only add the capabilities in the default set (AUDIT_WRITE, CHOWN, DAC_OVERRIDE, FOWNER, FSETID, KILL, MKNOD, NET_BIND_SERVICE, SETFCAP, SETGID, SETPCAP, SETUID and SYS_CHROOT)",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container shouldn't bind ports on the host
+	V1_CONTAINER_PSS_HOST_PORTS = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_HOST_PORTS",
+		Condition: func(container *v1.Container) bool {
+			for _, port := range container.Ports {
+				if port.HostPort != 0 {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The container shouldn't use host ports",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container's SELinux options should only set an allowed type
+	V1_CONTAINER_PSS_SELINUX = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_SELINUX",
+		Condition: func(container *v1.Container) bool {
+			return container.SecurityContext == nil || isSELinuxOptionAllowed(container.SecurityContext.SELinuxOptions)
+		},
+		Message: "The container's seLinuxOptions shouldn't set a user or role, and its type should be container_t, container_init_t or container_kvm_t",
+		Level:   log.ErrorLevel,
+	}
+	// A V1Container should use the default /proc mount
+	V1_CONTAINER_PSS_PROC_MOUNT = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_PROC_MOUNT",
+		Condition: func(container *v1.Container) bool {
+			return container.SecurityContext == nil || container.SecurityContext.ProcMount == nil ||
+				*container.SecurityContext.ProcMount == v1.DefaultProcMount
+		},
+		Message: "The container's procMount should be Default",
+		Level:   log.ErrorLevel,
+		Fix: func(container *v1.Container) bool {
+			if container.SecurityContext != nil {
+				container.SecurityContext.ProcMount = nil
+			}
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return "Removed procMount from container " + container.Name
+		},
+	}
+	// A V1Container should explicitly disallow privilege escalation
+	V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION",
+		Condition: func(container *v1.Container) bool {
+			return container.SecurityContext != nil && container.SecurityContext.AllowPrivilegeEscalation != nil &&
+				!*container.SecurityContext.AllowPrivilegeEscalation
+		},
+		Message: "The container's allowPrivilegeEscalation should be set to false",
+		Level:   log.ErrorLevel,
+		Fix: func(container *v1.Container) bool {
+			allowPrivilegeEscalation := false
+			if container.SecurityContext == nil {
+				container.SecurityContext = &v1.SecurityContext{}
+			}
+			container.SecurityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return "Set allowPrivilegeEscalation to false on container " + container.Name
+		},
+	}
+	// A V1Container should drop every capability
+	V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES",
+		Condition: func(container *v1.Container) bool {
+			if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+				return false
+			}
+			for _, capability := range container.SecurityContext.Capabilities.Drop {
+				if capability == "ALL" {
+					return true
+				}
+			}
+			return false
+		},
+		Message: "The container should drop ALL capabilities",
+		Level:   log.ErrorLevel,
+		Fix: func(container *v1.Container) bool {
+			if container.SecurityContext == nil {
+				container.SecurityContext = &v1.SecurityContext{}
+			}
+			if container.SecurityContext.Capabilities == nil {
+				container.SecurityContext.Capabilities = &v1.Capabilities{}
+			}
+			container.SecurityContext.Capabilities.Drop = append(container.SecurityContext.Capabilities.Drop, "ALL")
+			return true
+		},
+		FixDescription: func(container *v1.Container) string {
+			return "Dropped ALL capabilities from container " + container.Name
+		},
+	}
+	// A V1Container shouldn't add any capability but NET_BIND_SERVICE
+	V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES = &V1ContainerRule{
+		ID: "V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES",
+		Condition: func(container *v1.Container) bool {
+			if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+				return true
+			}
+			for _, capability := range container.SecurityContext.Capabilities.Add {
+				if capability != "NET_BIND_SERVICE" {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The container shouldn't add any capability but NET_BIND_SERVICE",
+		Level:   log.ErrorLevel,
+	}
+	// A pod's AppArmor annotations should only use the runtime's default profile or one on the node
+	GENERIC_PSS_APPARMOR = &GenericRule{
+		ID: "GENERIC_PSS_APPARMOR",
+		Condition: func(resource *Resource) bool {
+			annotations, _ := podAnnotations(resource)
+			for key, profile := range annotations {
+				if strings.HasPrefix(key, appArmorAnnotationPrefix) && profile != "runtime/default" && !strings.HasPrefix(profile, "localhost/") {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod's AppArmor annotations should be runtime/default or localhost/<profile>",
+		Level:   log.ErrorLevel,
+	}
+	// A pod's seccomp annotations shouldn't turn seccomp off
+	GENERIC_PSS_SECCOMP_BASELINE = &GenericRule{
+		ID: "GENERIC_PSS_SECCOMP_BASELINE",
+		Condition: func(resource *Resource) bool {
+			annotations, _ := podAnnotations(resource)
+			for key, profile := range annotations {
+				if (key == seccompPodAnnotation || strings.HasPrefix(key, seccompContainerAnnotationPrefix)) && profile == "unconfined" {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod's seccomp annotations shouldn't be unconfined",
+		Level:   log.ErrorLevel,
+	}
+	// A pod's seccomp annotations should confine every container
+	GENERIC_PSS_SECCOMP_RESTRICTED = &GenericRule{
+		ID: "GENERIC_PSS_SECCOMP_RESTRICTED",
+		Condition: func(resource *Resource) bool {
+			annotations, ok := podAnnotations(resource)
+			if !ok {
+				return true
+			}
+			_, podSpec, _ := podTemplate(resource.Object)
+			podProfile, podProfileSet := annotations[seccompPodAnnotation]
+			if podProfileSet && !isSeccompProfileConfined(podProfile) {
+				return false
+			}
+			for _, container := range containersOf(podSpec) {
+				profile, found := annotations[seccompContainerAnnotationPrefix+container.Name]
+				if (found && !isSeccompProfileConfined(profile)) || (!found && !podProfileSet) {
+					return false
+				}
+			}
+			return true
+		},
+		Message: "The pod's seccomp annotation (or one for every container) should be runtime/default or localhost/<profile>",
+		Level:   log.ErrorLevel,
+	}
+)
+
+var (
+	// ProfilePrivileged is the unrestricted standard, so it doesn't check anything.
+	ProfilePrivileged = &Profile{Name: "privileged"}
+	// ProfileBaseline prevents known privilege escalations, but lets pods run the way they do by default.
+	ProfileBaseline = &Profile{
+		Name:         "baseline",
+		GenericRules: []*GenericRule{GENERIC_PSS_APPARMOR, GENERIC_PSS_SECCOMP_BASELINE},
+		PodSpecRules: []*V1PodSpecRule{
+			V1_PODSPEC_PSS_HOST_NAMESPACES,
+			V1_PODSPEC_PSS_HOST_PATH_VOLUMES,
+			V1_PODSPEC_PSS_SELINUX,
+			V1_PODSPEC_PSS_SYSCTLS,
+		},
+		ContainerRules: []*V1ContainerRule{
+			V1_CONTAINER_PSS_PRIVILEGED,
+			V1_CONTAINER_PSS_BASELINE_CAPABILITIES,
+			V1_CONTAINER_PSS_HOST_PORTS,
+			V1_CONTAINER_PSS_SELINUX,
+			V1_CONTAINER_PSS_PROC_MOUNT,
+		},
+		Controls: baselineControls,
+	}
+	// ProfileRestricted follows the current pod hardening best practices, on top of everything in ProfileBaseline.
+	// Its seccomp and capabilities checks are stricter than the baseline ones, so they take their place.
+	ProfileRestricted = &Profile{
+		Name:         "restricted",
+		GenericRules: []*GenericRule{GENERIC_PSS_APPARMOR, GENERIC_PSS_SECCOMP_RESTRICTED},
+		PodSpecRules: []*V1PodSpecRule{
+			V1_PODSPEC_PSS_HOST_NAMESPACES,
+			V1_PODSPEC_PSS_HOST_PATH_VOLUMES,
+			V1_PODSPEC_PSS_SELINUX,
+			V1_PODSPEC_PSS_SYSCTLS,
+			V1_PODSPEC_PSS_VOLUME_TYPES,
+			V1_PODSPEC_PSS_RUN_AS_NON_ROOT,
+			V1_PODSPEC_PSS_RUN_AS_NON_ROOT_USER,
+		},
+		ContainerRules: []*V1ContainerRule{
+			V1_CONTAINER_PSS_PRIVILEGED,
+			V1_CONTAINER_PSS_HOST_PORTS,
+			V1_CONTAINER_PSS_SELINUX,
+			V1_CONTAINER_PSS_PROC_MOUNT,
+			V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION,
+			V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES,
+			V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES,
+		},
+		Controls: restrictedControls,
+	}
+)
+
+// baselineControls names the Pod Security Standards control each baseline rule checks.
+var baselineControls = map[RuleID]string{
+	"V1_PODSPEC_PSS_HOST_NAMESPACES":         "Host Namespaces",
+	"V1_PODSPEC_PSS_HOST_PATH_VOLUMES":       "HostPath Volumes",
+	"V1_PODSPEC_PSS_SELINUX":                 "SELinux",
+	"V1_PODSPEC_PSS_SYSCTLS":                 "Sysctls",
+	"V1_CONTAINER_PSS_PRIVILEGED":            "Privileged Containers",
+	"V1_CONTAINER_PSS_BASELINE_CAPABILITIES": "Capabilities",
+	"V1_CONTAINER_PSS_HOST_PORTS":            "Host Ports",
+	"V1_CONTAINER_PSS_SELINUX":               "SELinux",
+	"V1_CONTAINER_PSS_PROC_MOUNT":            "/proc Mount Type",
+	"GENERIC_PSS_APPARMOR":                   "AppArmor",
+	"GENERIC_PSS_SECCOMP_BASELINE":           "Seccomp",
+}
+
+// restrictedControls names the Pod Security Standards control each restricted rule checks.
+var restrictedControls = map[RuleID]string{
+	"V1_PODSPEC_PSS_HOST_NAMESPACES":              "Host Namespaces",
+	"V1_PODSPEC_PSS_HOST_PATH_VOLUMES":            "HostPath Volumes",
+	"V1_PODSPEC_PSS_SELINUX":                      "SELinux",
+	"V1_PODSPEC_PSS_SYSCTLS":                      "Sysctls",
+	"V1_PODSPEC_PSS_VOLUME_TYPES":                 "Volume Types",
+	"V1_PODSPEC_PSS_RUN_AS_NON_ROOT":              "Running as Non-root",
+	"V1_PODSPEC_PSS_RUN_AS_NON_ROOT_USER":         "Running as Non-root user",
+	"V1_CONTAINER_PSS_PRIVILEGED":                 "Privileged Containers",
+	"V1_CONTAINER_PSS_HOST_PORTS":                 "Host Ports",
+	"V1_CONTAINER_PSS_SELINUX":                    "SELinux",
+	"V1_CONTAINER_PSS_PROC_MOUNT":                 "/proc Mount Type",
+	"V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION": "Privilege Escalation",
+	"V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES":      "Capabilities",
+	"V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES":    "Capabilities",
+	"GENERIC_PSS_APPARMOR":                        "AppArmor",
+	"GENERIC_PSS_SECCOMP_RESTRICTED":              "Seccomp",
+}
@@ -0,0 +1,259 @@
+package kubelint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacV1 "k8s.io/api/rbac/v1"
+	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// access is a verb on a resource in an API group, eg get secrets in the core ("") group.
+type access struct {
+	verb     string
+	apiGroup string
+	resource string
+}
+
+// The accesses the predefined RBAC rules look out for. Having any one of them in a set is enough to be flagged.
+var (
+	secretsReadAccess = []access{{"get", "", "secrets"}, {"list", "", "secrets"}, {"watch", "", "secrets"}}
+	podExecAccess     = []access{{"create", "", "pods/exec"}, {"get", "", "pods/exec"}}
+	// escalate and bind let you grant permissions you don't have, and impersonate lets you act as someone who has them
+	privilegeEscalationAccess = []access{
+		{"escalate", rbacV1.GroupName, "roles"}, {"escalate", rbacV1.GroupName, "clusterroles"},
+		{"bind", rbacV1.GroupName, "roles"}, {"bind", rbacV1.GroupName, "clusterroles"},
+		{"impersonate", "", "users"}, {"impersonate", "", "groups"}, {"impersonate", "", "serviceaccounts"},
+	}
+)
+
+// clusterAdminRules are the rules of the built-in cluster-admin ClusterRole.
+var clusterAdminRules = []rbacV1.PolicyRule{
+	{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+	{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}},
+}
+
+// matchesRBAC tells you if a verb, API group or resource in a policy rule covers the value, the way the RBAC authorizer does:
+// "*" covers everything, and "*/<subresource>" covers that subresource of any resource.
+func matchesRBAC(values []string, value string) bool {
+	for _, v := range values {
+		if v == rbacV1.ResourceAll || v == value {
+			return true
+		}
+		if strings.HasPrefix(v, "*/") && strings.Contains(value, "/") && strings.HasSuffix(value, v[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows tells you if the policy rule grants any of the accesses (for at least some resource names).
+func allows(rule rbacV1.PolicyRule, accesses []access) bool {
+	for _, a := range accesses {
+		if matchesRBAC(rule.Verbs, a.verb) && matchesRBAC(rule.APIGroups, a.apiGroup) && matchesRBAC(rule.Resources, a.resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWildcard tells you if the policy rule uses "*" for its verbs or resources.
+func hasWildcard(rule rbacV1.PolicyRule) bool {
+	for _, value := range append(append([]string{}, rule.Verbs...), rule.Resources...) {
+		if value == rbacV1.ResourceAll {
+			return true
+		}
+	}
+	return false
+}
+
+// anyRule tells you if any of the policy rules satisfies the check.
+func anyRule(rules []rbacV1.PolicyRule, check func(rbacV1.PolicyRule) bool) bool {
+	for _, rule := range rules {
+		if check(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAny makes a check (for anyRule) out of a list of accesses.
+func allowsAny(accesses []access) func(rbacV1.PolicyRule) bool {
+	return func(rule rbacV1.PolicyRule) bool {
+		return allows(rule, accesses)
+	}
+}
+
+// riskyPermissions are what INTERDEPENDENT_SERVICE_ACCOUNT_RISKY_PERMISSIONS looks for in a ServiceAccount's effective permissions,
+// each with how it finishes a message, eg "ServiceAccount orchard/pear can read secrets".
+var riskyPermissions = []struct {
+	description string
+	check       func(rbacV1.PolicyRule) bool
+}{
+	{"use wildcard verbs or resources", hasWildcard},
+	{"escalate, bind or impersonate", allowsAny(privilegeEscalationAccess)},
+	{"read secrets", allowsAny(secretsReadAccess)},
+	{"exec into pods", allowsAny(podExecAccess)},
+}
+
+// roleRules gives you the policy rules of a Role or ClusterRole of any API version.
+// The last return value is false if the object isn't a role.
+func roleRules(object metav1.Object) ([]rbacV1.PolicyRule, bool) {
+	switch role := object.(type) {
+	case *rbacV1.Role:
+		return role.Rules, true
+	case *rbacV1.ClusterRole:
+		return role.Rules, true
+	case *rbacV1beta1.Role:
+		return convertPolicyRules(role.Rules), true
+	case *rbacV1beta1.ClusterRole:
+		return convertPolicyRules(role.Rules), true
+	}
+	return nil, false
+}
+
+func convertPolicyRules(rules []rbacV1beta1.PolicyRule) []rbacV1.PolicyRule {
+	var converted []rbacV1.PolicyRule
+	for _, rule := range rules {
+		converted = append(converted, rbacV1.PolicyRule(rule))
+	}
+	return converted
+}
+
+// RoleGrant is a role that a binding in the unit grants to a ServiceAccount.
+type RoleGrant struct {
+	Namespace string              // the namespace the role applies in, or empty if it applies across the cluster
+	Binding   *Resource           // the RoleBinding or ClusterRoleBinding that grants the role
+	RoleRef   rbacV1.RoleRef      // the role that's granted
+	Rules     []rbacV1.PolicyRule // what the role allows, or nil if it isn't in the unit (cluster-admin is the exception)
+}
+
+// where describes where the role applies, eg "in namespace orchard" or "across the cluster"
+func (g *RoleGrant) where() string {
+	if g.Namespace == "" {
+		return "across the cluster"
+	}
+	return "in namespace " + g.Namespace
+}
+
+// via describes where the role comes from, eg "ClusterRole view via RoleBinding pear-viewer"
+func (g *RoleGrant) via() string {
+	return fmt.Sprintf("%s %s via %s", g.RoleRef.Kind, g.RoleRef.Name, kindAndName(g.Binding))
+}
+
+// ServiceAccountPermissions is everything the bindings in a unit let a ServiceAccount do.
+type ServiceAccountPermissions struct {
+	Namespace string
+	Name      string
+	Grants    []*RoleGrant
+}
+
+// String summarises the permissions, one line per policy rule, eg
+//
+//	ServiceAccount orchard/pear
+//	  in namespace orchard: get configmaps (Role pear-reader via RoleBinding pear-reader)
+func (p *ServiceAccountPermissions) String() string {
+	lines := []string{fmt.Sprintf("ServiceAccount %s/%s", p.Namespace, p.Name)}
+	for _, grant := range p.Grants {
+		if grant.Rules == nil {
+			lines = append(lines, fmt.Sprintf("  %s: whatever %s allows", grant.where(), grant.via()))
+			continue
+		}
+		for _, rule := range grant.Rules {
+			lines = append(lines, fmt.Sprintf("  %s: %s (%s)", grant.where(), describePolicyRule(rule), grant.via()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describePolicyRule describes what a policy rule allows, eg "get,list secrets named db-password" or "get nonResourceURLs /healthz"
+func describePolicyRule(rule rbacV1.PolicyRule) string {
+	verbs := strings.Join(rule.Verbs, ",")
+	if len(rule.NonResourceURLs) != 0 {
+		return fmt.Sprintf("%s nonResourceURLs %s", verbs, strings.Join(rule.NonResourceURLs, ","))
+	}
+	var resources []string
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			if group != "" {
+				resource += "." + group
+			}
+			resources = append(resources, resource)
+		}
+	}
+	description := fmt.Sprintf("%s %s", verbs, strings.Join(resources, ","))
+	if len(rule.ResourceNames) != 0 {
+		description += " named " + strings.Join(rule.ResourceNames, ",")
+	}
+	return description
+}
+
+// EffectivePermissions works out what every ServiceAccount in the unit (or bound to a role in it) is allowed to do,
+// by following each RoleBinding and ClusterRoleBinding to its role. Subjects can be a ServiceAccount,
+// or the system:serviceaccounts groups that include every ServiceAccount (in a namespace).
+// The ServiceAccounts are sorted by namespace and name.
+func EffectivePermissions(graph *ResourceGraph) []*ServiceAccountPermissions {
+	accounts := make(map[string]*ServiceAccountPermissions)
+	account := func(namespace string, name string) *ServiceAccountPermissions {
+		key := namespace + "/" + name
+		if accounts[key] == nil {
+			accounts[key] = &ServiceAccountPermissions{Namespace: namespace, Name: name}
+		}
+		return accounts[key]
+	}
+	for _, resource := range graph.Kind("ServiceAccount") {
+		account(resource.Object.GetNamespace(), resource.Object.GetName())
+	}
+	type groupGrant struct {
+		namespace string // the namespace of a system:serviceaccounts:<namespace> group, or empty for every ServiceAccount
+		grant     *RoleGrant
+	}
+	var groupGrants []groupGrant
+	for _, resource := range graph.Resources() {
+		binding, ok := asRoleBinding(resource.Object)
+		if !ok {
+			continue
+		}
+		grant := &RoleGrant{Namespace: binding.namespace, Binding: resource, RoleRef: binding.roleRef}
+		for _, edge := range graph.From(resource, EdgeBindsRole) {
+			if rules, ok := roleRules(edge.To.Object); ok {
+				grant.Rules = append(grant.Rules, rules...)
+			}
+		}
+		if grant.Rules == nil && binding.roleRef.Kind == "ClusterRole" && binding.roleRef.Name == "cluster-admin" {
+			grant.Rules = clusterAdminRules
+		}
+		for _, subject := range binding.subjects {
+			switch {
+			case subject.Kind == rbacV1.ServiceAccountKind:
+				namespace := subject.Namespace
+				if namespace == "" {
+					namespace = binding.namespace
+				}
+				account(namespace, subject.Name).Grants = append(account(namespace, subject.Name).Grants, grant)
+			case subject.Kind == rbacV1.GroupKind && subject.Name == "system:serviceaccounts":
+				groupGrants = append(groupGrants, groupGrant{"", grant})
+			case subject.Kind == rbacV1.GroupKind && strings.HasPrefix(subject.Name, "system:serviceaccounts:"):
+				groupGrants = append(groupGrants, groupGrant{strings.TrimPrefix(subject.Name, "system:serviceaccounts:"), grant})
+			}
+		}
+	}
+	var permissions []*ServiceAccountPermissions
+	for _, p := range accounts {
+		for _, g := range groupGrants {
+			if g.namespace == "" || g.namespace == p.Namespace {
+				p.Grants = append(p.Grants, g.grant)
+			}
+		}
+		permissions = append(permissions, p)
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Namespace != permissions[j].Namespace {
+			return permissions[i].Namespace < permissions[j].Namespace
+		}
+		return permissions[i].Name < permissions[j].Name
+	})
+	return permissions
+}
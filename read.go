@@ -66,35 +66,47 @@ func ReadBytes(bytes []byte, filepath string) ([]*YamlDerivedResource, []error)
 			errors = append(errors, fmt.Errorf("Empty YAML document found in %s", filepath))
 		}
 		// 2. Decode the object into its corresponding k8s type (eg *appsv1.Deployment)
-		concrete, _, err := scheme.Codecs.UniversalDeserializer().Decode(marshalledResource, nil, nil)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("UniversalDeserializer.Decode: %s, maybe the YAML document in %s can't conform to the runtime.Object interface", err, filepath))
-			continue
+		line := 0
+		if currentObjNum < len(lineNumber) {
+			line = lineNumber[currentObjNum]
 		}
-		// 3. Try to get the object to conform to these easy-to-use interfaces
-		typeInfo, err := meta.TypeAccessor(concrete)
+		resource, err := decodeResource(marshalledResource, filepath, line)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("Kubernetes object in %s does not conform to the meta.Type interface, so it cannot be interpreted by this tool", filepath))
-			continue
-		}
-		object, ok := concrete.(metav1.Object)
-		if !ok {
-			errors = append(errors, fmt.Errorf("Kubernetes object in %s does not conform to the metav1.Object interface, so it cannot be interpreted by this tool", filepath))
+			errors = append(errors, err)
 			continue
 		}
-		resources = append(resources, &YamlDerivedResource{
-			Filepath:   filepath,
-			LineNumber: lineNumber[currentObjNum],
-			Resource: Resource{
-				TypeInfo: typeInfo,
-				Object:   object,
-			},
-		})
+		resources = append(resources, resource)
 		currentObjNum++
 	}
 	return resources, errors
 }
 
+// decodeResource decodes a single YAML document into its concrete kubernetes type (eg *appsv1.Deployment)
+// and makes sure it conforms to the interfaces needed to build a Resource.
+func decodeResource(document []byte, filepath string, lineNumber int) (*YamlDerivedResource, error) {
+	concrete, _, err := scheme.Codecs.UniversalDeserializer().Decode(document, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("UniversalDeserializer.Decode: %s, maybe the YAML document in %s can't conform to the runtime.Object interface", err, filepath)
+	}
+	// Try to get the object to conform to these easy-to-use interfaces
+	typeInfo, err := meta.TypeAccessor(concrete)
+	if err != nil {
+		return nil, fmt.Errorf("Kubernetes object in %s does not conform to the meta.Type interface, so it cannot be interpreted by this tool", filepath)
+	}
+	object, ok := concrete.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("Kubernetes object in %s does not conform to the metav1.Object interface, so it cannot be interpreted by this tool", filepath)
+	}
+	return &YamlDerivedResource{
+		Filepath:   filepath,
+		LineNumber: lineNumber,
+		Resource: Resource{
+			TypeInfo: typeInfo,
+			Object:   object,
+		},
+	}, nil
+}
+
 // copied from https://github.com/instrumenta/kubeval/blob/9c9c0a5b3cc619dbd94129af77c8512bfd0f1763/kubeval/utils.go#L24
 func detectLineBreak(haystack []byte) string {
 	windowsLineEnding := bytesPkg.Contains(haystack, []byte("\r\n"))
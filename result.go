@@ -9,4 +9,7 @@ type Result struct {
 	Resources []*YamlDerivedResource // the resource(s) on which the rule was performed to get this result
 	Message   string                 // the complaining message (eg "no securityContextKey present")
 	Level     log.Level              // the level of trouble this result causes
+	RuleID    RuleID                 // the ID of the rule that produced this result
+	Container string                 // the container a container rule failed on, empty for every other kind of rule
+	Control   string                 // the Pod Security Standards control the rule checks, if it was added as part of a Profile
 }
@@ -1,13 +1,20 @@
 package kubelint
 
 import (
+	"fmt"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
 	networkingV1 "k8s.io/api/networking/v1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
 	rbacV1 "k8s.io/api/rbac/v1"
 	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
 )
@@ -24,6 +31,21 @@ type rule struct {
 	Resources      []*YamlDerivedResource
 	Fix            func() bool // should mutate the underlying resource references in `Resources` somehow
 	FixDescription func() string
+	violations     []*violation // filled in by interdependent rules that report each violation separately
+	container      string       // the container a container rule checks, since there's one rule for every container in the pod
+}
+
+// key identifies the rule among the other rules of a resource. Container rules are told apart by their container,
+// eg V1_CONTAINER_PRIVILEGED_FALSE[app].
+func (r *rule) key() RuleID {
+	if r.container == "" {
+		return r.ID
+	}
+	return containerRuleKey(r.ID, r.container)
+}
+
+func containerRuleKey(id RuleID, container string) RuleID {
+	return RuleID(fmt.Sprintf("%s[%s]", id, container))
 }
 
 // AppsV1DeploymentRule represents a semantic enforcement. For example, you would like all appsv1.Deployments to
@@ -173,8 +195,9 @@ type V1ContainerRule struct {
 // to interpolate.
 func (r *V1ContainerRule) createRule(container *v1.Container, ydr *YamlDerivedResource) *rule {
 	rule := &rule{
-		ID:      r.ID,
-		Prereqs: r.Prereqs,
+		ID:        r.ID,
+		Prereqs:   r.Prereqs,
+		container: container.Name,
 		Condition: func() bool {
 			if r.Condition == nil {
 				return true
@@ -411,6 +434,48 @@ func (r *V1Beta1ExtensionsIngressRule) createRule(ingress *v1beta1Extensions.Ing
 	return rule
 }
 
+//	NetworkingV1beta1IngressRule represents a generic linter rule that can be applied to any networkingV1beta1.Ingress object.
+type NetworkingV1beta1IngressRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*networkingV1beta1.Ingress) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*networkingV1beta1.Ingress) bool
+	FixDescription func(*networkingV1beta1.Ingress) string
+}
+
+// createRule transforms a <ResourceType>Rule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *NetworkingV1beta1IngressRule) createRule(ingress *networkingV1beta1.Ingress, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(ingress)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(ingress)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(ingress)
+		},
+	}
+	return rule
+}
+
 //	NetworkingV1NetworkPolicyRule represents a generic linter rule that can be applied to any networkingV1.NetworkPolicy object.
 type NetworkingV1NetworkPolicyRule struct {
 	ID             RuleID
@@ -579,6 +644,132 @@ func (r *RbacV1Beta1RoleBindingRule) createRule(rolebinding *rbacV1beta1.RoleBin
 	return rule
 }
 
+//	RbacV1RoleBindingRule represents a generic linter rule that can be applied to any rbacV1.RoleBinding object.
+type RbacV1RoleBindingRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*rbacV1.RoleBinding) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*rbacV1.RoleBinding) bool
+	FixDescription func(*rbacV1.RoleBinding) string
+}
+
+// createRule transforms a RbacV1RoleBindingRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *RbacV1RoleBindingRule) createRule(rolebinding *rbacV1.RoleBinding, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(rolebinding)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(rolebinding)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(rolebinding)
+		},
+	}
+	return rule
+}
+
+//	RbacV1ClusterRoleRule represents a generic linter rule that can be applied to any rbacV1.ClusterRole object.
+type RbacV1ClusterRoleRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*rbacV1.ClusterRole) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*rbacV1.ClusterRole) bool
+	FixDescription func(*rbacV1.ClusterRole) string
+}
+
+// createRule transforms a RbacV1ClusterRoleRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *RbacV1ClusterRoleRule) createRule(clusterrole *rbacV1.ClusterRole, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(clusterrole)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(clusterrole)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(clusterrole)
+		},
+	}
+	return rule
+}
+
+//	RbacV1ClusterRoleBindingRule represents a generic linter rule that can be applied to any rbacV1.ClusterRoleBinding object.
+type RbacV1ClusterRoleBindingRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*rbacV1.ClusterRoleBinding) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*rbacV1.ClusterRoleBinding) bool
+	FixDescription func(*rbacV1.ClusterRoleBinding) string
+}
+
+// createRule transforms a RbacV1ClusterRoleBindingRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *RbacV1ClusterRoleBindingRule) createRule(clusterrolebinding *rbacV1.ClusterRoleBinding, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(clusterrolebinding)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(clusterrolebinding)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(clusterrolebinding)
+		},
+	}
+	return rule
+}
+
 //	V1ServiceAccountRule represents a generic linter rule that can be applied to any v1.ServiceAccount object.
 type V1ServiceAccountRule struct {
 	ID             RuleID
@@ -663,6 +854,216 @@ func (r *V1ServiceRule) createRule(service *v1.Service, ydr *YamlDerivedResource
 	return rule
 }
 
+//	V1ConfigMapRule represents a generic linter rule that can be applied to any v1.ConfigMap object.
+type V1ConfigMapRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*v1.ConfigMap) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*v1.ConfigMap) bool
+	FixDescription func(*v1.ConfigMap) string
+}
+
+// createRule transforms a V1ConfigMapRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *V1ConfigMapRule) createRule(configmap *v1.ConfigMap, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(configmap)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(configmap)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(configmap)
+		},
+	}
+	return rule
+}
+
+//	V1SecretRule represents a generic linter rule that can be applied to any v1.Secret object.
+type V1SecretRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*v1.Secret) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*v1.Secret) bool
+	FixDescription func(*v1.Secret) string
+}
+
+// createRule transforms a V1SecretRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *V1SecretRule) createRule(secret *v1.Secret, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(secret)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(secret)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(secret)
+		},
+	}
+	return rule
+}
+
+//	AutoscalingV1HorizontalPodAutoscalerRule represents a generic linter rule that can be applied to any autoscalingV1.HorizontalPodAutoscaler object.
+type AutoscalingV1HorizontalPodAutoscalerRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*autoscalingV1.HorizontalPodAutoscaler) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*autoscalingV1.HorizontalPodAutoscaler) bool
+	FixDescription func(*autoscalingV1.HorizontalPodAutoscaler) string
+}
+
+// createRule transforms a AutoscalingV1HorizontalPodAutoscalerRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *AutoscalingV1HorizontalPodAutoscalerRule) createRule(autoscaler *autoscalingV1.HorizontalPodAutoscaler, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(autoscaler)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(autoscaler)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(autoscaler)
+		},
+	}
+	return rule
+}
+
+//	AutoscalingV2beta2HorizontalPodAutoscalerRule represents a generic linter rule that can be applied to any autoscalingV2beta2.HorizontalPodAutoscaler object.
+type AutoscalingV2beta2HorizontalPodAutoscalerRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*autoscalingV2beta2.HorizontalPodAutoscaler) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*autoscalingV2beta2.HorizontalPodAutoscaler) bool
+	FixDescription func(*autoscalingV2beta2.HorizontalPodAutoscaler) string
+}
+
+// createRule transforms a AutoscalingV2beta2HorizontalPodAutoscalerRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *AutoscalingV2beta2HorizontalPodAutoscalerRule) createRule(autoscaler *autoscalingV2beta2.HorizontalPodAutoscaler, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(autoscaler)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(autoscaler)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(autoscaler)
+		},
+	}
+	return rule
+}
+
+//	PolicyV1beta1PodDisruptionBudgetRule represents a generic linter rule that can be applied to any policyV1beta1.PodDisruptionBudget object.
+type PolicyV1beta1PodDisruptionBudgetRule struct {
+	ID             RuleID
+	Prereqs        []RuleID
+	Condition      func(*policyV1beta1.PodDisruptionBudget) bool
+	Message        string
+	Level          log.Level
+	Fix            func(*policyV1beta1.PodDisruptionBudget) bool
+	FixDescription func(*policyV1beta1.PodDisruptionBudget) string
+}
+
+// createRule transforms a PolicyV1beta1PodDisruptionBudgetRule into a generic rule once it receives the parameter
+// to interpolate.
+func (r *PolicyV1beta1PodDisruptionBudgetRule) createRule(budget *policyV1beta1.PodDisruptionBudget, ydr *YamlDerivedResource) *rule {
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Condition: func() bool {
+			if r.Condition == nil {
+				return true
+			}
+			return r.Condition(budget)
+		},
+		Message:   r.Message,
+		Level:     r.Level,
+		Resources: []*YamlDerivedResource{ydr},
+		Fix: func() bool {
+			if r.Fix == nil {
+				return false
+			}
+			return r.Fix(budget)
+		},
+		FixDescription: func() string {
+			if r.FixDescription == nil {
+				return ""
+			}
+			return r.FixDescription(budget)
+		},
+	}
+	return rule
+}
+
 //	GenericRule represents a generic linter rule that can be applied to an object of any type.
 //	Use this if the type you want to apply a check to is not currently supported, or it's a check
 //	that can apply uniformly to all resources, for example, each resource is registered under a namespace.
@@ -710,64 +1111,132 @@ func (r *GenericRule) createRule(resource *Resource, ydr *YamlDerivedResource) *
 //	InterdependentRule represents a generic linter rule that will be applied to the resources as a whole.
 //	An example would be to check that for all objects, their namespace corresponds to an existing namespace object.
 //	You will need to do your own typecasting or rely on the methods available to you in metav1.Object and meta.Type to access the objects' fields.
+//	If your rule needs to know how resources relate to each other (which pods a Service selects, which Role a RoleBinding grants...)
+//	set GraphCondition instead of Condition, and it'll be handed a ResourceGraph that is built once for the whole unit.
+//	If you set Violations instead, it's handed the graph too, and each Violation it returns is reported as a separate result with its own message and fix.
 type InterdependentRule struct {
-	ID             RuleID
-	Condition      func([]*Resource) (bool, []*Resource) // if it returns false, it will also return a list of the offending resources. This is passed to the result.Resources field later.
-	Message        string
-	Level          log.Level
-	Fix            func([]*Resource) bool
-	FixDescription func([]*Resource) string
+	ID                      RuleID
+	Prereqs                 []RuleID                                                  // other interdependent rules that have to pass before this one is evaluated
+	Condition               func([]*Resource) (bool, []*Resource)                     // if it returns false, it will also return a list of the offending resources. This is passed to the result.Resources field later.
+	GraphCondition          func(*ResourceGraph) (bool, []*Resource)                  // used instead of Condition if it's set
+	Violations              func(*ResourceGraph) []*Violation                         // used instead of Condition and GraphCondition if it's set. The rule passes if there are no violations.
+	Message                 string                                                    // the message of any Violation that doesn't have its own
+	MessageFunc             func(resources []*Resource, offending []*Resource) string // if set, it's used instead of Message so you can say exactly what's wrong with the offending resources
+	Level                   log.Level
+	Fix                     func([]*Resource) bool // not used with Violations, a Violation has its own Fix
+	FixDescription          func([]*Resource) string
+	FixOffending            func(resources []*Resource, offending []*Resource) bool   // used instead of Fix if it's set. It should only change the offending resources.
+	FixOffendingDescription func(resources []*Resource, offending []*Resource) string // used instead of FixDescription if it's set
 }
 
-type interdependentRule struct {
-	ID             RuleID
-	Condition      func() bool // if it returns false, it will also return a list of the offending resources. This is passed to the result.Resources field later.
-	Message        string
-	Level          log.Level
-	Fix            func() bool
-	FixDescription func() string
-	Resources      []*YamlDerivedResource
+// Violation is one way the resources in a unit break an interdependent rule, usually because of a single resource.
+// Each violation is reported as its own Result.
+type Violation struct {
+	Resources      []*Resource   // the resources at fault, usually just one
+	Message        string        // what's wrong, eg "Deployment pear is in namespace apple but the unit's namespace is orchard"
+	Fix            func() bool   // fixes just this violation, if it can be fixed
+	FixDescription func() string // describes the fix once it's been applied
+}
+
+// violation is a Violation once its resources have been traced back to where they were read from.
+type violation struct {
+	resources []*YamlDerivedResource
+	message   string
 }
 
 // createRule transforms a InterdependentRule into a generic rule once it receives the parameter
-// to interpolate.
-func (r *InterdependentRule) createRule(resources []*YamlDerivedResource) *interdependentRule {
+// to interpolate. The graph must have been built from the same resources, and can be nil if the rule has no GraphCondition or Violations.
+// Nothing is evaluated until the rule's Condition is called, which is also when the offending resources
+// (and the violations, or the message if there's a MessageFunc) are filled in.
+func (r *InterdependentRule) createRule(resources []*YamlDerivedResource, graph *ResourceGraph) *rule {
 	var bareResources []*Resource
 	for _, r := range resources {
 		bareResources = append(bareResources, &r.Resource)
 	}
-	// we need to silently execute the condition so we can find out which resources are relevant :(
-	// This means prerequisites are disallowed. sorry :(
-	success, offendingResources := r.Condition(bareResources)
 	// collect information about offending resources
-	var offendingYamls []*YamlDerivedResource
-	for _, offendingResource := range offendingResources {
-		for _, yaml := range resources {
-			if &yaml.Resource == offendingResource {
-				offendingYamls = append(offendingYamls, yaml)
+	findYamls := func(offendingResources []*Resource) []*YamlDerivedResource {
+		var offendingYamls []*YamlDerivedResource
+		for _, offendingResource := range offendingResources {
+			for _, yaml := range resources {
+				if &yaml.Resource == offendingResource {
+					offendingYamls = append(offendingYamls, yaml)
+				}
 			}
 		}
+		return offendingYamls
 	}
-	rule := &interdependentRule{
-		ID: r.ID,
-		Condition: func() bool {
-			return success
-		},
-		Message:   r.Message,
-		Level:     r.Level,
-		Resources: offendingYamls,
+	var offendingResources []*Resource // what the Condition returned, handed to FixOffending
+	var violations []*Violation
+	var fixed []*Violation
+	rule := &rule{
+		ID:      r.ID,
+		Prereqs: r.Prereqs,
+		Message: r.Message,
+		Level:   r.Level,
 		Fix: func() bool {
+			if r.Violations != nil {
+				fixed = nil
+				for _, v := range violations {
+					if v.Fix != nil && v.Fix() {
+						fixed = append(fixed, v)
+					}
+				}
+				return len(fixed) != 0
+			}
+			if r.FixOffending != nil {
+				return r.FixOffending(bareResources, offendingResources)
+			}
 			if r.Fix == nil {
 				return false
 			}
 			return r.Fix(bareResources)
 		},
 		FixDescription: func() string {
+			if r.Violations != nil {
+				var descriptions []string
+				for _, v := range fixed {
+					if v.FixDescription != nil {
+						descriptions = append(descriptions, v.FixDescription())
+					}
+				}
+				return strings.Join(descriptions, "; ")
+			}
+			if r.FixOffendingDescription != nil {
+				return r.FixOffendingDescription(bareResources, offendingResources)
+			}
 			if r.FixDescription == nil {
 				return ""
 			}
 			return r.FixDescription(bareResources)
 		},
 	}
+	rule.Condition = func() bool {
+		if r.Violations != nil {
+			violations = r.Violations(graph)
+			for _, v := range violations {
+				offendingYamls := findYamls(v.Resources)
+				message := v.Message
+				if message == "" {
+					message = r.Message
+				}
+				rule.Resources = append(rule.Resources, offendingYamls...)
+				rule.violations = append(rule.violations, &violation{resources: offendingYamls, message: message})
+			}
+			return len(violations) == 0
+		}
+		var success bool
+		if r.GraphCondition != nil {
+			success, offendingResources = r.GraphCondition(graph)
+		} else if r.Condition != nil {
+			success, offendingResources = r.Condition(bareResources)
+		} else {
+			return true
+		}
+		rule.Resources = findYamls(offendingResources)
+		if !success && r.MessageFunc != nil {
+			rule.Message = r.MessageFunc(bareResources, offendingResources)
+		}
+		return success
+	}
 	return rule
 }
@@ -11,6 +11,19 @@ import "fmt"
 type ruleSorter struct {
 	rules map[RuleID]*rule
 	edges map[RuleID]map[RuleID]RuleID
+	order []RuleID // the order the rules were given in, so ties are always broken the same way
+}
+
+// touches tells whether any of the rules apply to one of the resources.
+func (r *ruleSorter) touches(resources map[*YamlDerivedResource]bool) bool {
+	for _, rule := range r.rules {
+		for _, resource := range rule.Resources {
+			if resources[resource] {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Retrieve the rule given its ID
@@ -32,22 +45,98 @@ func (r *ruleSorter) clone() *ruleSorter {
 			edgesClone[id][incoming] = incoming
 		}
 	}
-	return &ruleSorter{edges: edgesClone, rules: rulesClone}
+	orderClone := append([]RuleID(nil), r.order...)
+	return &ruleSorter{edges: edgesClone, rules: rulesClone, order: orderClone}
 }
 
 // Create a new ruleSorter given a list of rules
 // Usual use case is to use the ruleSorter to access the rules in the correct order!
-func newRuleSorter(rules []*rule) *ruleSorter {
+// A rule whose prerequisite isn't one of the rules, or that's part of a cycle of prerequisites, can never be evaluated,
+// so it's left out along with every rule that depends on it, and a RuleError is returned for each of them.
+func newRuleSorter(rules []*rule) (*ruleSorter, []error) {
 	e := make(map[RuleID]map[RuleID]RuleID)
 	r := make(map[RuleID]*rule)
+	var order []RuleID
 	for _, rule := range rules {
-		r[rule.ID] = rule
-		e[rule.ID] = make(map[RuleID]RuleID)
+		key := rule.key()
+		if _, found := r[key]; !found {
+			order = append(order, key)
+		}
+		r[key] = rule
+		e[key] = make(map[RuleID]RuleID)
 		for _, prereq := range rule.Prereqs {
-			e[rule.ID][prereq] = prereq
+			e[key][prereq] = prereq
+		}
+	}
+	sorter := &ruleSorter{edges: e, rules: r, order: order}
+	return sorter, sorter.leaveOutUnorderable()
+}
+
+// leaveOutUnorderable removes the rules that can't be ordered (see newRuleSorter) and explains why each of them was left out.
+func (r *ruleSorter) leaveOutUnorderable() []error {
+	var errors []error
+	leftOut := func(id RuleID, err error) {
+		errors = append(errors, &RuleError{RuleID: r.rules[id].ID, Resources: r.rules[id].Resources, Err: err})
+	}
+	// work out which rules can be ordered by evaluating them the way popNextAvailable would
+	remaining := r.clone()
+	for _, id := range r.order {
+		for prereq := range r.edges[id] {
+			if _, found := r.rules[prereq]; !found {
+				leftOut(id, fmt.Errorf("its prerequisite %s isn't registered", prereq))
+			}
+		}
+	}
+	for remaining.popNextAvailable() != nil {
+	}
+	for _, id := range r.order {
+		if _, stuck := remaining.edges[id]; !stuck {
+			continue
+		}
+		delete(r.edges, id)
+		switch {
+		case remaining.inCycle(id):
+			leftOut(id, fmt.Errorf("it's part of a cycle of prerequisites"))
+		case len(missingPrereqs(remaining.edges[id], r.rules)) == 0:
+			for prereq := range remaining.edges[id] {
+				leftOut(id, fmt.Errorf("its prerequisite %s was left out", prereq))
+				break
+			}
+		}
+	}
+	return errors
+}
+
+// missingPrereqs gives you the prerequisites that aren't among the rules.
+func missingPrereqs(prereqs map[RuleID]RuleID, rules map[RuleID]*rule) []RuleID {
+	var missing []RuleID
+	for prereq := range prereqs {
+		if _, found := rules[prereq]; !found {
+			missing = append(missing, prereq)
+		}
+	}
+	return missing
+}
+
+// inCycle tells you if the rule is (indirectly) one of its own prerequisites.
+func (r *ruleSorter) inCycle(id RuleID) bool {
+	seen := make(map[RuleID]bool)
+	var visit func(RuleID) bool
+	visit = func(current RuleID) bool {
+		for prereq := range r.edges[current] {
+			if prereq == id {
+				return true
+			}
+			if !seen[prereq] {
+				seen[prereq] = true
+				if visit(prereq) {
+					return true
+				}
+			}
 		}
+		return false
 	}
-	return &ruleSorter{edges: e, rules: r}
+	return visit(id)
 }
 
 func (r *ruleSorter) getDependentRules(masterId RuleID) []*rule {
@@ -64,7 +153,7 @@ func (r *ruleSorter) getDependentRules(masterId RuleID) []*rule {
 // 	Ie, you would never have a rule dependent on another if they are referring to different objects.
 func (r *ruleSorter) getDependents(masterId RuleID) []RuleID {
 	var dependentIDs []RuleID
-	for id := range r.rules {
+	for _, id := range r.order {
 		for _, masterRuleID := range r.rules[id].Prereqs {
 			if masterRuleID == masterId {
 				if _, ok := r.edges[id]; ok {
@@ -106,15 +195,11 @@ func (r *ruleSorter) popDependentRules(masterId RuleID) []*rule {
 	dependents := r.getDependentRules(masterId)
 	// now just delete them from the map.
 	for _, rule := range dependents {
-		delete(r.edges, rule.ID)
+		delete(r.edges, rule.key())
 	}
 	return dependents
 }
 
-func (r *ruleSorter) isEmpty() bool {
-	return len(r.edges) == 0
-}
-
 //	This method removes the given rule from the ruleSorter structure.
 //	For example, when a rule is satisfied and we don't have to worry about the fix
 //	methods attached to a rule, remove the rule from the structure.
@@ -130,33 +215,26 @@ func (r *ruleSorter) remove(id RuleID) {
 }
 
 // When you need to know which rule you should execute next, call this method. It will remove
-// the rule from the data structure and return it.
+// the rule from the data structure and return it, or return nil once there's nothing left to evaluate.
 // The algorithm is as follows:
 //
-//1. Find a rule with no dependencies, in case of multiple such rules the first one is chosen
+//1. Find a rule with no dependencies, in case of multiple such rules the first one given to newRuleSorter is chosen
 //2. Find all the rules which depend on this rule, and remove it from it's dependency list
 //3. remove the rule itself from the edge map
 //4. Return the rule
 func (r *ruleSorter) popNextAvailable() *rule {
 	var ruleId RuleID
-	cycle := true
-	for id, incoming := range r.edges {
-		if len(incoming) == 0 {
+	available := false
+	for _, id := range r.order {
+		if incoming, ok := r.edges[id]; ok && len(incoming) == 0 {
 			ruleId = id
-			cycle = false
+			available = true
 			break
 		}
 	}
-	// If we don't have any empty edges list, that means
-	// we have a cycle somewhere
-	if cycle {
-		for id, edges := range r.edges {
-			fmt.Printf("%s:\n", id)
-			for rule, _ := range edges {
-				fmt.Printf("\t%s\n", rule)
-			}
-		}
-		panic("Either there's a cycle in your dependencies OR you've forgotten to include a prerequisite rule. Please be more careful")
+	// newRuleSorter leaves out the rules that can't be ordered, so this only happens once every rule has been popped
+	if !available {
+		return nil
 	}
 	for _, id := range r.getDependents(ruleId) {
 		// update their edges so that they don't remember ruleId anymore!
@@ -0,0 +1,77 @@
+package kubelint
+
+import (
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingV2beta2 "k8s.io/api/autoscaling/v2beta2"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// autoscalerReplicas gives you the smallest and largest number of replicas a HorizontalPodAutoscaler of any API version
+// scales to. minReplicas is 1 when it's left out. The last return value is false if the object isn't an autoscaler.
+func autoscalerReplicas(object metav1.Object) (int32, int32, bool) {
+	var minReplicas *int32
+	var maxReplicas int32
+	switch autoscaler := object.(type) {
+	case *autoscalingV1.HorizontalPodAutoscaler:
+		minReplicas, maxReplicas = autoscaler.Spec.MinReplicas, autoscaler.Spec.MaxReplicas
+	case *autoscalingV2beta1.HorizontalPodAutoscaler:
+		minReplicas, maxReplicas = autoscaler.Spec.MinReplicas, autoscaler.Spec.MaxReplicas
+	case *autoscalingV2beta2.HorizontalPodAutoscaler:
+		minReplicas, maxReplicas = autoscaler.Spec.MinReplicas, autoscaler.Spec.MaxReplicas
+	default:
+		return 0, 0, false
+	}
+	if minReplicas == nil {
+		return 1, maxReplicas, true
+	}
+	return *minReplicas, maxReplicas, true
+}
+
+// expectedReplicas gives you the fewest pods a workload in the graph can be running: the smallest minReplicas of
+// the autoscalers that scale it, or else its own replicas. The last return value is false if the workload
+// doesn't have replicas (eg a DaemonSet, whose number of pods depends on the cluster).
+func expectedReplicas(graph *ResourceGraph, workload *Resource) (int32, bool) {
+	field, ok := replicas(workload.Object)
+	if !ok {
+		return 0, false
+	}
+	found := false
+	var fewest int32
+	for _, edge := range graph.To(workload, EdgeScales) {
+		if minReplicas, _, ok := autoscalerReplicas(edge.From.Object); ok && (!found || minReplicas < fewest) {
+			fewest, found = minReplicas, true
+		}
+	}
+	if found {
+		return fewest, true
+	}
+	if *field == nil {
+		return 1, true
+	}
+	return **field, true
+}
+
+// disruptionsAllowed works out how many of the pods a PodDisruptionBudget covers can be evicted at once when all of them
+// are running, the way the disruption controller does (percentages are rounded up, and a budget that sets neither
+// minAvailable nor maxUnavailable is given a minAvailable of 1). It returns an error if either is a malformed percentage.
+func disruptionsAllowed(budget *policyV1beta1.PodDisruptionBudget, pods int32) (int32, error) {
+	if budget.Spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetValueFromIntOrPercent(budget.Spec.MaxUnavailable, int(pods), true)
+		if err != nil {
+			return 0, err
+		}
+		return int32(maxUnavailable), nil
+	}
+	minAvailable := intstr.FromInt(1)
+	if budget.Spec.MinAvailable != nil {
+		minAvailable = *budget.Spec.MinAvailable
+	}
+	available, err := intstr.GetValueFromIntOrPercent(&minAvailable, int(pods), true)
+	if err != nil {
+		return 0, err
+	}
+	return pods - int32(available), nil
+}
@@ -0,0 +1,62 @@
+package kubelint
+
+import (
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// clusterScopedKinds are the built-in kinds that don't live in a namespace, in every API version of their group.
+// The scheme resources are read with knows every built-in kind, but not where it lives, and without an API server
+// to ask there's nothing to derive that from, so this is a static table that has to be kept up to date by hand.
+// A kind that's missing from it is treated as namespaced, so give the linter a RESTMapper with Linter.SetRESTMapper
+// to tell it about the kinds that aren't in it, like cluster scoped custom resources.
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "ComponentStatus"}:                                            true,
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "auditregistration.k8s.io", Kind: "AuditSink"}:                          true,
+	{Group: "authentication.k8s.io", Kind: "TokenReview"}:                           true,
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectAccessReview"}:                true,
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectRulesReview"}:                 true,
+	{Group: "authorization.k8s.io", Kind: "SubjectAccessReview"}:                    true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "extensions", Kind: "PodSecurityPolicy"}:                                true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                     true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:     true,
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                              true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                      true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+}
+
+// isNamespaced tells you if the resource should live in a namespace.
+// Kinds that aren't in clusterScopedKinds (like custom resources) are assumed to be namespaced, since most of them are.
+func isNamespaced(resource *Resource) bool {
+	gvk := schema.FromAPIVersionAndKind(resource.TypeInfo.GetAPIVersion(), resource.TypeInfo.GetKind())
+	return !clusterScopedKinds[gvk.GroupKind()]
+}
+
+// isNamespacedBy asks the mapper (if there is one) whether the resource should live in a namespace,
+// falling back to isNamespaced for the kinds the mapper doesn't know about.
+func isNamespacedBy(mapper meta.RESTMapper, resource *Resource) bool {
+	if mapper == nil {
+		return isNamespaced(resource)
+	}
+	gvk := schema.FromAPIVersionAndKind(resource.TypeInfo.GetAPIVersion(), resource.TypeInfo.GetKind())
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return isNamespaced(resource)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
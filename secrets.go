@@ -0,0 +1,159 @@
+package kubelint
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The API server rejects a ConfigMap or Secret whose data adds up to more than dataSizeLimit bytes,
+// and the predefined rules warn about anything over dataSizeWarning.
+const (
+	dataSizeLimit   = 1024 * 1024
+	dataSizeWarning = dataSizeLimit * 9 / 10
+)
+
+// The thresholds (in bits per character) over which a word is random enough to be a credential rather than prose,
+// a path or an identifier. A word of n characters can't have more than log2(n) bits per character, so words of 22 characters
+// or fewer can never reach the base64 limit, and random base64 words only reliably do from about 32 characters on
+// (half of them at 32, nearly all of them at 40). Hex has 16 symbols, so it can't go over 4 bits per character,
+// and a random 32 character hex word comes out between about 3.3 and 3.9.
+const (
+	minCredentialLength = 32
+	base64EntropyLimit  = 4.5
+	hexEntropyLimit     = 3.3
+)
+
+// Hex words this long are almost always SHA-1 or SHA-256 hashes, eg git commits and image digests, rather than credentials,
+// and they're just as random.
+var hashLengths = map[int]bool{40: true, 64: true}
+
+const (
+	base64Characters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/="
+	hexCharacters    = "0123456789abcdefABCDEF"
+)
+
+var (
+	awsAccessKeyID   = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	privateKeyHeader = regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+)
+
+// containsCredential tells you if the value looks like it has a credential in it: an AWS access key ID,
+// the header of a PEM (or PGP) private key, or a long word that's too random to be anything but a key or a token.
+// It only ever says whether it found something, so the value can't end up in a message by accident.
+func containsCredential(value string) bool {
+	if awsAccessKeyID.MatchString(value) || privateKeyHeader.MatchString(value) {
+		return true
+	}
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool { return !strings.ContainsRune(base64Characters, r) }) {
+		if len(word) < minCredentialLength {
+			continue
+		}
+		limit := base64EntropyLimit
+		if strings.Trim(word, hexCharacters) == "" {
+			if hashLengths[len(word)] {
+				continue
+			}
+			limit = hexEntropyLimit
+		}
+		if shannonEntropy(word) > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy gives you the average number of bits of information in each character of the word.
+func shannonEntropy(word string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range word {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len(word))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// configMapHasCredentials tells you if any value in the ConfigMap's data looks like it holds a credential.
+// binaryData isn't checked, since binary data is always random looking.
+func configMapHasCredentials(configmap *v1.ConfigMap) bool {
+	for _, value := range configmap.Data {
+		if containsCredential(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// configMapSize adds up the size of the keys and values of the ConfigMap's data and binaryData, the way the API server does.
+func configMapSize(configmap *v1.ConfigMap) int {
+	size := 0
+	for key, value := range configmap.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range configmap.BinaryData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// secretSize adds up the size of the keys and values of the Secret's data (decoded) and stringData,
+// since the API server merges stringData into data before checking it.
+func secretSize(secret *v1.Secret) int {
+	size := 0
+	for key, value := range secret.Data {
+		if _, overwritten := secret.StringData[key]; !overwritten {
+			size += len(key) + len(value)
+		}
+	}
+	for key, value := range secret.StringData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// configMapKeys gives you the keys of the ConfigMap's data and binaryData.
+func configMapKeys(configmap *v1.ConfigMap) []string {
+	var keys []string
+	for key := range configmap.Data {
+		keys = append(keys, key)
+	}
+	for key := range configmap.BinaryData {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// secretKeys gives you the keys of the Secret's data and stringData, leaving out the data keys stringData overwrites.
+func secretKeys(secret *v1.Secret) []string {
+	var keys []string
+	for key := range secret.Data {
+		if _, overwritten := secret.StringData[key]; !overwritten {
+			keys = append(keys, key)
+		}
+	}
+	for key := range secret.StringData {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// validDataKeys tells you if the keys are all valid ConfigMap or Secret keys (alphanumeric characters, '-', '_' or '.'),
+// and none of them is used twice, eg in both the data and binaryData of a ConfigMap.
+func validDataKeys(keys []string) bool {
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if len(validation.IsConfigMapKey(key)) != 0 || seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
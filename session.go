@@ -0,0 +1,426 @@
+package kubelint
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Session is a single run of a Linter. It owns everything produced while linting:
+// the resources that were read in, the results and the fixes waiting to be applied.
+// Throw the session away once you're done with it and start a new one with Linter.NewSession;
+// the rules registered in the Linter are shared between sessions.
+type Session struct {
+	linter              *Linter
+	logger              *log.Logger
+	fixes               []*ruleSorter            // fixes that should be applied to the resources in order to mitigate some errors on a future pass
+	interdependentFixes []*ruleSorter            // fixes for the interdependent rules that failed, applied after the others
+	resources           []*Resource              // All the resources that have been read in by this session
+	units               [][]*YamlDerivedResource // The resources read in by each Lint call, so they can be linted again once fixed
+	loose               []*YamlDerivedResource   // The resources passed straight to LintResource, which don't belong to a unit
+	results             []*Result                // The results of linting the resources in their current state
+	abandonedMutex      sync.Mutex               // guards abandoned, since the workers give up on conditions at the same time
+	abandoned           []*abandonedCondition    // conditions that timed out, which mustn't have their resources fixed until they return
+}
+
+// Resources returns every resource that has been read in by this session.
+func (s *Session) Resources() []*Resource {
+	return s.resources
+}
+
+// Results returns the results of linting everything in this session so far.
+// After FixUntilStable, these are the results of the final lint pass.
+func (s *Session) Results() []*Result {
+	return s.results
+}
+
+// Lint opens and lints the files and produces results that
+// can be logged later on. The files are treated as one unit by the interdependent rules.
+func (s *Session) Lint(filepaths ...string) ([]*Result, []error) {
+	return s.LintContext(context.Background(), filepaths...)
+}
+
+// LintContext is like Lint, but stops linting as soon as the context is done.
+func (s *Session) LintContext(ctx context.Context, filepaths ...string) ([]*Result, []error) {
+	s.logger.Debugf("Linting files: %#v\n", filepaths)
+	resources, errors := Read(filepaths...)
+	results, errs := s.lintUnit(ctx, resources)
+	return results, append(errors, errs...)
+}
+
+// LintBytes takes a slice of bytes to lint and a filepath and
+// returns a list of Results and errors to report or log later on
+func (s *Session) LintBytes(data []byte, filepath string) ([]*Result, []error) {
+	return s.LintBytesContext(context.Background(), data, filepath)
+}
+
+// LintBytesContext is like LintBytes, but stops linting as soon as the context is done.
+func (s *Session) LintBytesContext(ctx context.Context, data []byte, filepath string) ([]*Result, []error) {
+	resources, errors := ReadBytes(data, filepath)
+	results, errs := s.lintUnit(ctx, resources)
+	return results, append(errors, errs...)
+}
+
+// LintFile takes a file pointer and returns a list of Reults and Errors
+// to be logged or reported later on
+func (s *Session) LintFile(file *os.File) ([]*Result, []error) {
+	return s.LintFileContext(context.Background(), file)
+}
+
+// LintFileContext is like LintFile, but stops linting as soon as the context is done.
+func (s *Session) LintFileContext(ctx context.Context, file *os.File) ([]*Result, []error) {
+	resources, errors := ReadFile(file)
+	results, errs := s.lintUnit(ctx, resources)
+	return results, append(errors, errs...)
+}
+
+// lintUnit remembers the resources read in by a single Lint call as a unit,
+// so that they can be fixed (and linted again) later on, then lints them.
+func (s *Session) lintUnit(ctx context.Context, resources []*YamlDerivedResource) ([]*Result, []error) {
+	for _, resource := range resources {
+		s.resources = append(s.resources, &resource.Resource)
+	}
+	s.units = append(s.units, resources)
+	results, errors := s.lintYamlDerivedResources(ctx, resources)
+	s.results = append(s.results, results...)
+	return results, errors
+}
+
+// lintYamlDerivedResources applies the interdependent rules to the resources as a whole
+// and then every other rule to each resource individually.
+// If the context is done part way through, the results found so far are returned along with the context's error.
+func (s *Session) lintYamlDerivedResources(ctx context.Context, resources []*YamlDerivedResource) ([]*Result, []error) {
+	// add interdependent checks
+	results, errors := s.lintResources(ctx, resources)
+	for i, outcome := range s.lintEach(ctx, resources) {
+		if outcome == nil {
+			// the context was done before we got to this resource
+			continue
+		}
+		s.logger.Debugln("results from linting", resources[i].Filepath, outcome.results)
+		results = append(results, outcome.results...)
+		s.fixes = append(s.fixes, outcome.fixes)
+		for _, err := range outcome.errors {
+			s.logger.Debugln("Error from LintResource: ", err)
+		}
+		errors = append(errors, outcome.errors...)
+	}
+	if err := ctx.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	return results, errors
+}
+
+// resourceOutcome is everything that comes out of linting a single resource.
+// Workers only ever write to their own outcome, so nothing is shared between resources.
+type resourceOutcome struct {
+	results []*Result
+	fixes   *ruleSorter
+	errors  []error
+}
+
+// lintEach lints every resource on its own, spreading the resources across the linter's workers.
+// The outcomes are returned in the same order as the resources, however many workers there are.
+// Resources that weren't linted because the context was done have a nil outcome.
+func (s *Session) lintEach(ctx context.Context, resources []*YamlDerivedResource) []*resourceOutcome {
+	outcomes := make([]*resourceOutcome, len(resources))
+	workers := s.linter.workers
+	if workers > len(resources) {
+		workers = len(resources)
+	}
+	if workers <= 1 {
+		for i, resource := range resources {
+			if ctx.Err() != nil {
+				break
+			}
+			outcomes[i] = s.lintResource(ctx, resource)
+		}
+		return outcomes
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				outcomes[i] = s.lintResource(ctx, resources[i])
+			}
+		}()
+	}
+	for i := range resources {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return outcomes
+}
+
+// lintResources takes a list of Yaml Derived Resources, applying interdependent rules ONLY
+// and returns a list of Results
+// to be logged or reported
+func (s *Session) lintResources(ctx context.Context, resources []*YamlDerivedResource) ([]*Result, []error) {
+	var results []*Result
+	if len(s.linter.interdependentRules) == 0 {
+		return results, nil
+	}
+	ruleSorter, errors := newRuleSorter(s.linter.createInterdependentRules(resources))
+	fixSorter := ruleSorter.clone()
+	for rule := ruleSorter.popNextAvailable(); rule != nil; rule = ruleSorter.popNextAvailable() {
+		passed, finished, err := evaluate(ctx, s.linter.ruleTimeout, rule.Condition)
+		if finished != nil {
+			s.abandon(&abandonedCondition{rule: rule, finished: finished})
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			// the condition may still be running, so rule.Resources can't be trusted
+			errors = append(errors, &RuleError{RuleID: rule.ID, Err: err})
+			_ = ruleSorter.popDependentRules(rule.key())
+			_ = fixSorter.popDependentRules(rule.key())
+			fixSorter.remove(rule.key())
+			continue
+		}
+		if passed {
+			fixSorter.remove(rule.key())
+			continue
+		}
+		if len(rule.violations) == 0 {
+			results = append(results, &Result{
+				Resources: rule.Resources,
+				Message:   rule.Message,
+				Level:     rule.Level,
+				RuleID:    rule.ID,
+			})
+		}
+		for _, violation := range rule.violations {
+			results = append(results, &Result{
+				Resources: violation.resources,
+				Message:   violation.message,
+				Level:     rule.Level,
+				RuleID:    rule.ID,
+			})
+		}
+		// the dependent rules can't be evaluated safely, so they're reported against the resources that failed the prerequisite
+		for _, dependentRule := range ruleSorter.popDependentRules(rule.key()) {
+			results = append(results, &Result{
+				Resources: rule.Resources,
+				Message:   dependentRule.Message,
+				Level:     dependentRule.Level,
+				RuleID:    dependentRule.ID,
+			})
+		}
+	}
+	s.interdependentFixes = append(s.interdependentFixes, fixSorter)
+	return results, errors
+}
+
+// LintResource takes a yaml derived resource and returns a list of results and errors
+// to be logged or reported. The resource is kept by the session so it can be fixed,
+// but it isn't part of any unit so no interdependent rules are applied to it.
+// Only the first error is returned, use LintResourceContext to get all of them.
+func (s *Session) LintResource(resource *YamlDerivedResource) ([]*Result, error) {
+	results, errors := s.LintResourceContext(context.Background(), resource)
+	if len(errors) != 0 {
+		return results, errors[0]
+	}
+	return results, nil
+}
+
+// LintResourceContext is like LintResource, but stops linting as soon as the context is done.
+func (s *Session) LintResourceContext(ctx context.Context, resource *YamlDerivedResource) ([]*Result, []error) {
+	s.resources = append(s.resources, &resource.Resource)
+	s.loose = append(s.loose, resource)
+	outcome := s.lintResource(ctx, resource)
+	s.fixes = append(s.fixes, outcome.fixes)
+	s.results = append(s.results, outcome.results...)
+	if err := ctx.Err(); err != nil {
+		return outcome.results, append(outcome.errors, err)
+	}
+	return outcome.results, outcome.errors
+}
+
+// lintResource applies every rule that isn't interdependent to the resource.
+// It only touches the session to remember any condition it gives up on, so it's safe to call for different resources at the same time.
+// A rule whose Condition panics or times out is reported as a RuleError, and the rules depending on it are skipped.
+func (s *Session) lintResource(ctx context.Context, resource *YamlDerivedResource) *resourceOutcome {
+	var results []*Result
+	var errors []error
+	rules, err := s.linter.createRules(resource)
+	if err != nil {
+		errors = append(errors, err)
+	}
+	s.logger.Debugln(len(rules), "rules created for", resource.Filepath)
+	// log rules and their dependent rules
+	for _, rule := range rules {
+		s.logger.Debugf("Rule ID: %s\n\tPrereqs: %#v\n", rule.key(), rule.Prereqs)
+	}
+	ruleSorter, sortErrors := newRuleSorter(rules)
+	errors = append(errors, sortErrors...)
+	fixSorter := ruleSorter.clone()
+	for rule := ruleSorter.popNextAvailable(); rule != nil; rule = ruleSorter.popNextAvailable() {
+		s.logger.Debugln("Testing rule", rule.key())
+		passed, finished, err := evaluate(ctx, s.linter.ruleTimeout, rule.Condition)
+		if finished != nil {
+			s.abandon(&abandonedCondition{rule: rule, resource: resource, finished: finished})
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			s.logger.Debugln("Rule errored:", err)
+			errors = append(errors, &RuleError{RuleID: rule.ID, Resources: rule.Resources, Err: err})
+			// we have no idea whether this rule (or anything depending on it) holds, so don't try to fix any of them
+			_ = ruleSorter.popDependentRules(rule.key())
+			_ = fixSorter.popDependentRules(rule.key())
+			fixSorter.remove(rule.key())
+			continue
+		}
+		if !passed {
+			s.logger.Debugln("Rule failed")
+			results = append(results, &Result{
+				Resources: []*YamlDerivedResource{resource},
+				Message:   rule.Message,
+				Level:     rule.Level,
+				RuleID:    rule.ID,
+				Container: rule.container,
+				Control:   s.linter.controls[rule.ID],
+			})
+			s.logger.Debugf("Adding result: %#v\n", results[len(results)-1])
+			dependentRules := ruleSorter.popDependentRules(rule.key())
+			s.logger.Debugf("Dependent rules:\n")
+			for _, rule := range dependentRules {
+				s.logger.Debugln(rule.ID)
+			}
+			for _, dependentRule := range dependentRules {
+				results = append(results, &Result{
+					Resources: []*YamlDerivedResource{resource},
+					Message:   dependentRule.Message,
+					Level:     dependentRule.Level,
+					RuleID:    dependentRule.ID,
+					Container: dependentRule.container,
+					Control:   s.linter.controls[dependentRule.ID],
+				})
+			}
+		} else {
+			// this doesn't need to be fixed, so remove it from the fixSorter
+			fixSorter.remove(rule.key())
+		}
+	}
+	return &resourceOutcome{results: results, fixes: fixSorter, errors: errors}
+}
+
+// ApplyFixes applies all fixes that were registered as necessary during the lint phase.
+// The references to all the objects are kept in the Resources array so it will be reflected there.
+// Once applied, the fixes are forgotten, so calling ApplyFixes again won't apply them twice.
+// A fix that panics is skipped, use ApplyFixesContext to find out about it.
+func (s *Session) ApplyFixes() ([]*Resource, []string) {
+	resources, descriptions, _ := s.ApplyFixesContext(context.Background())
+	return resources, descriptions
+}
+
+// ApplyFixesContext is like ApplyFixes, but stops applying fixes once the context is done,
+// and reports a RuleError for every fix that panicked.
+func (s *Session) ApplyFixesContext(ctx context.Context) ([]*Resource, []string, []error) {
+	var appliedFixDescriptions []string
+	applied, errors := s.applyFixes(ctx)
+	for _, fix := range applied {
+		appliedFixDescriptions = append(appliedFixDescriptions, fix.Description)
+	}
+	return s.resources, appliedFixDescriptions, errors
+}
+
+// applyFixes applies and then forgets every pending fix, returning the ones that succeeded.
+// While a Condition that timed out is still running, the fixes that could change what it's reading are skipped:
+// the fixes for its resource, the interdependent fixes (which can change any resource) and, if it's an interdependent
+// Condition, every fix. A RuleError is reported for each Condition that's holding fixes back.
+func (s *Session) applyFixes(ctx context.Context) ([]*appliedFix, []error) {
+	var applied []*appliedFix
+	var errors []error
+	busy := make(map[*YamlDerivedResource]bool)
+	everything := false
+	for _, condition := range s.stillRunning() {
+		errors = append(errors, stillRunningError(condition))
+		if condition.resource == nil {
+			everything = true
+		}
+		busy[condition.resource] = true
+	}
+	sorters := s.fixes
+	if len(busy) == 0 {
+		// the interdependent fixes go last, so they see the resources once they've been fixed individually
+		sorters = append(append([]*ruleSorter{}, s.fixes...), s.interdependentFixes...)
+	}
+	if everything {
+		sorters = nil
+	}
+	for _, sorter := range sorters {
+		if sorter.touches(busy) {
+			continue
+		}
+		for rule := sorter.popNextAvailable(); rule != nil && ctx.Err() == nil; rule = sorter.popNextAvailable() {
+			fix, err := applyFix(rule.ID, rule.Resources, rule.Fix, rule.FixDescription)
+			if err != nil {
+				errors = append(errors, err)
+			}
+			if fix == nil {
+				_ = sorter.popDependentRules(rule.key())
+			} else {
+				applied = append(applied, fix)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	s.fixes = nil
+	s.interdependentFixes = nil
+	return applied, errors
+}
+
+// applyFix calls a rule's Fix (and FixDescription if it worked), recovering from any panic.
+// It returns nil if the fix couldn't be applied.
+func applyFix(id RuleID, resources []*YamlDerivedResource, fix func() bool, fixDescription func() string) (*appliedFix, error) {
+	var description string
+	fixed, err := callRecovered(func() bool {
+		if !fix() {
+			return false
+		}
+		description = fixDescription()
+		return true
+	})
+	if err != nil {
+		return nil, &RuleError{RuleID: id, Resources: resources, Err: err}
+	}
+	if !fixed {
+		return nil, nil
+	}
+	return &appliedFix{ID: id, Resources: resources, Description: description}, nil
+}
+
+// abandon remembers a condition that was given up on, so its resource isn't fixed while it's still running.
+func (s *Session) abandon(condition *abandonedCondition) {
+	s.abandonedMutex.Lock()
+	defer s.abandonedMutex.Unlock()
+	s.abandoned = append(s.abandoned, condition)
+}
+
+// stillRunning forgets about the conditions that have returned since they were given up on, and returns the rest.
+func (s *Session) stillRunning() []*abandonedCondition {
+	s.abandonedMutex.Lock()
+	defer s.abandonedMutex.Unlock()
+	var running []*abandonedCondition
+	for _, condition := range s.abandoned {
+		if condition.running() {
+			running = append(running, condition)
+		}
+	}
+	s.abandoned = running
+	return running
+}
@@ -0,0 +1,207 @@
+package kubelint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ReadStream decodes the YAML documents in r one at a time, calling fn with each resource as soon as it has been decoded,
+// so the whole input never has to be held in memory. Documents made up of nothing but whitespace and comments
+// (which helm template likes to produce) are skipped. Return false from fn to stop reading.
+func ReadStream(r io.Reader, filepath string, fn func(*YamlDerivedResource) bool) []error {
+	var errors []error
+	reader := bufio.NewReader(r)
+	var document []byte
+	lineNumber := 0
+	objectLineNumber := 0 // the line the apiVersion key of the current document is on, just like findLineNumbers
+	// flush decodes the document read in so far, returning false if we should stop reading
+	flush := func() bool {
+		defer func() {
+			document = nil
+			objectLineNumber = 0
+		}()
+		if isBlankDocument(document) {
+			return true
+		}
+		resource, err := decodeResource(document, filepath, objectLineNumber)
+		if err != nil {
+			errors = append(errors, err)
+			return true
+		}
+		return fn(resource)
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 {
+			lineNumber++
+			if isDocumentSeparator(line) {
+				if !flush() {
+					return errors
+				}
+			} else {
+				if objectLineNumber == 0 && bytes.Contains(line, []byte("apiVersion:")) {
+					objectLineNumber = lineNumber
+				}
+				document = append(document, line...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return append(errors, err)
+		}
+	}
+	flush()
+	return errors
+}
+
+// isDocumentSeparator tells you if the line is the --- separating two YAML documents.
+func isDocumentSeparator(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t\r\n")) == "---"
+}
+
+// isBlankDocument tells you if the document has no content other than whitespace and comments.
+func isBlankDocument(document []byte) bool {
+	for _, line := range bytes.Split(document, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) != 0 && trimmed[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// LintStream lints the YAML documents in r as they are read, calling report with every result as soon as it's found.
+// Unlike LintBytes, the resources aren't kept around once they've been linted, so this is the way to lint enormous inputs,
+// like the helm template output of a whole platform. The documents are treated as one unit: once the stream
+// has been read, the interdependent rules are applied to lightweight copies of the resources.
+// Since the resources aren't kept, there are no fixes to apply afterwards.
+func (l *Linter) LintStream(ctx context.Context, r io.Reader, filepath string, report func(*Result)) []error {
+	s := l.NewSession()
+	var errors []error
+	var index []*YamlDerivedResource // lightweight copies of the resources for the interdependent rules
+	batchSize := l.workers
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	var batch []*YamlDerivedResource
+	lintBatch := func() {
+		for _, outcome := range s.lintEach(ctx, batch) {
+			if outcome == nil {
+				continue
+			}
+			for _, result := range outcome.results {
+				report(result)
+			}
+			errors = append(errors, outcome.errors...)
+		}
+		batch = nil
+	}
+	readErrors := ReadStream(r, filepath, func(resource *YamlDerivedResource) bool {
+		if len(l.interdependentRules) != 0 {
+			index = append(index, lightweightCopy(resource))
+		}
+		batch = append(batch, resource)
+		if len(batch) >= batchSize {
+			lintBatch()
+		}
+		return ctx.Err() == nil
+	})
+	lintBatch()
+	errors = append(errors, readErrors...)
+	if ctx.Err() == nil && len(l.interdependentRules) != 0 {
+		results, errs := s.lintResources(ctx, index)
+		for _, result := range results {
+			report(result)
+		}
+		errors = append(errors, errs...)
+	}
+	if err := ctx.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	return errors
+}
+
+// LintReader is like LintStream, but sends the results on a channel. The results channel is closed
+// once the stream has been linted, and then the errors are sent (exactly once) on the second channel.
+// Linting waits for each result to be received, so you must either read the results channel until it's closed
+// or cancel the context if you stop early. Otherwise the goroutine doing the linting is stuck sending forever.
+func (l *Linter) LintReader(ctx context.Context, r io.Reader, filepath string) (<-chan *Result, <-chan []error) {
+	results := make(chan *Result)
+	errors := make(chan []error, 1)
+	go func() {
+		errs := l.LintStream(ctx, r, filepath, func(result *Result) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		})
+		close(results)
+		errors <- errs
+		close(errors)
+	}()
+	return results, errors
+}
+
+// lightweightCopy copies a resource for the interdependent rules that run at the end of a stream, leaving out
+// the parts that take up the most memory and that interdependent rules have no business looking at:
+// the last applied configuration, the managed fields, and the values (but not the keys) of ConfigMaps and Secrets.
+// Everything else is kept, since interdependent rules follow references from anywhere in a spec (a container's env,
+// a volume, an Ingress backend, a role's rules...), so the copies still take up about as much memory as the specs themselves.
+func lightweightCopy(ydr *YamlDerivedResource) *YamlDerivedResource {
+	object, ok := ydr.Resource.Object.(runtime.Object)
+	if !ok {
+		return ydr
+	}
+	copied := object.DeepCopyObject()
+	switch concrete := copied.(type) {
+	case *v1.ConfigMap:
+		for key := range concrete.Data {
+			concrete.Data[key] = ""
+		}
+		for key := range concrete.BinaryData {
+			concrete.BinaryData[key] = nil
+		}
+	case *v1.Secret:
+		for key := range concrete.Data {
+			concrete.Data[key] = nil
+		}
+		for key := range concrete.StringData {
+			concrete.StringData[key] = ""
+		}
+	}
+	typeInfo, err := meta.TypeAccessor(copied)
+	if err != nil {
+		return ydr
+	}
+	copiedObject, ok := copied.(metav1.Object)
+	if !ok {
+		return ydr
+	}
+	if _, found := copiedObject.GetAnnotations()[v1.LastAppliedConfigAnnotation]; found {
+		annotations := make(map[string]string)
+		for key, value := range copiedObject.GetAnnotations() {
+			if key != v1.LastAppliedConfigAnnotation {
+				annotations[key] = value
+			}
+		}
+		copiedObject.SetAnnotations(annotations)
+	}
+	copiedObject.SetManagedFields(nil)
+	return &YamlDerivedResource{
+		Resource: Resource{
+			TypeInfo: typeInfo,
+			Object:   copiedObject,
+		},
+		Filepath:   ydr.Filepath,
+		LineNumber: ydr.LineNumber,
+	}
+}
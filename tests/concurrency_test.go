@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+// manyDeployments generates a unit of n deployments that break a few of the predefined rules.
+func manyDeployments(n int) []byte {
+	var documents []string
+	for i := 0; i < n; i++ {
+		documents = append(documents, fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-%d
+spec:
+  template:
+    metadata:
+      labels:
+        app: deployment-%d
+    spec:
+      containers:
+      - name: app
+        image: nginx:latest
+      - name: sidecar
+        image: busybox
+`, i, i))
+	}
+	return []byte(strings.Join(documents, "---\n"))
+}
+
+func newBenchmarkLinter() *kubelint.Linter {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(
+		kubelint.APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL,
+		kubelint.APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL,
+		kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE,
+		kubelint.APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS,
+		kubelint.APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS,
+		kubelint.APPSV1_DEPLOYMENT_LIVENESS_READINESS_NONMATCHING,
+	)
+	linter.AddV1PodSpecRule(
+		kubelint.V1_PODSPEC_NON_NIL_SECURITY_CONTEXT,
+		kubelint.V1_PODSPEC_RUN_AS_NON_ROOT,
+		kubelint.V1_PODSPEC_CORRECT_USER_GROUP_ID,
+		kubelint.V1_PODSPEC_EXACTLY_1_CONTAINER,
+		kubelint.V1_PODSPEC_NON_ZERO_CONTAINERS,
+	)
+	linter.AddV1ContainerRule(
+		kubelint.V1_CONTAINER_EXISTS_SECURITY_CONTEXT,
+		kubelint.V1_CONTAINER_ALLOW_PRIVILEGE_ESCALATION_FALSE,
+		kubelint.V1_CONTAINER_VALID_IMAGE,
+		kubelint.V1_CONTAINER_PRIVILEGED_FALSE,
+		kubelint.V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS,
+		kubelint.V1_CONTAINER_REQUESTS_CPU_REASONABLE,
+	)
+	return linter
+}
+
+// describeResults flattens the results so that two runs can be compared.
+func describeResults(results []*kubelint.Result) []string {
+	var descriptions []string
+	for _, result := range results {
+		var names []string
+		for _, resource := range result.Resources {
+			names = append(names, resource.Resource.Object.GetName())
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s %v", result.RuleID, names))
+	}
+	return descriptions
+}
+
+// expectResults checks that the results are the expected ones, in any order. Each is written as "RULE_ID subject: message",
+// where the subject is the container the result is about or, if there isn't one, the name of its first resource.
+func expectResults(t *testing.T, results []*kubelint.Result, expected ...string) {
+	t.Helper()
+	var got []string
+	for _, result := range results {
+		subject := result.Container
+		if subject == "" && len(result.Resources) != 0 {
+			subject = result.Resources[0].Resource.Object.GetName()
+		}
+		got = append(got, fmt.Sprintf("%s %s: %s", result.RuleID, subject, result.Message))
+	}
+	want := append([]string{}, expected...)
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected %d results\n\t%s\ngot %d\n\t%s", len(want), strings.Join(want, "\n\t"), len(got), strings.Join(got, "\n\t"))
+	}
+}
+
+func TestConcurrentLintingIsDeterministic(t *testing.T) {
+	data := manyDeployments(50)
+	sequential := newBenchmarkLinter()
+	expected, errs := sequential.LintBytes(data, "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	concurrent := newBenchmarkLinter()
+	concurrent.SetWorkers(8)
+	session := concurrent.NewSession()
+	for run := 0; run < 5; run++ {
+		results, errs := session.LintBytes(data, "FAKE.yaml")
+		for _, err := range errs {
+			t.Error(err)
+		}
+		want, got := describeResults(expected), describeResults(results)
+		if len(want) != len(got) {
+			t.Fatalf("Expected %d results, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				t.Fatalf("Result %d differs: expected %s, got %s", i, want[i], got[i])
+			}
+		}
+	}
+	_, fixDescriptions := session.ApplyFixes()
+	if len(fixDescriptions) == 0 {
+		t.Errorf("Expected fixes to be collected from every worker")
+	}
+}
+
+func benchmarkLint(b *testing.B, workers int) {
+	data := manyDeployments(1000)
+	linter := newBenchmarkLinter()
+	linter.SetWorkers(workers)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linter.LintBytes(data, "FAKE.yaml")
+	}
+}
+
+func BenchmarkLintSequential(b *testing.B) {
+	benchmarkLint(b, 1)
+}
+
+func BenchmarkLintFourWorkers(b *testing.B) {
+	benchmarkLint(b, 4)
+}
+
+func BenchmarkLintAllCPUs(b *testing.B) {
+	benchmarkLint(b, runtime.NumCPU())
+}
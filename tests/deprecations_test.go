@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+)
+
+const deprecatedUnit = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: pear:1.0
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: apple:1.0
+---
+apiVersion: rbac.authorization.k8s.io/v1beta1
+kind: RoleBinding
+metadata:
+  name: pear-viewer
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: default
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: prune
+  namespace: orchard
+spec:
+  schedule: "0 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: prune
+            image: prune:1.0
+---
+apiVersion: authentication.k8s.io/v1beta1
+kind: TokenReview
+metadata:
+  name: check
+spec:
+  token: not-a-real-token
+`
+
+func TestParseKubernetesVersion(t *testing.T) {
+	for version, expected := range map[string]kubelint.KubernetesVersion{
+		"1.22":                {Major: 1, Minor: 22},
+		"v1.16.3":             {Major: 1, Minor: 16},
+		"v1.21.5-eks-bc4871b": {Major: 1, Minor: 21},
+	} {
+		parsed, err := kubelint.ParseKubernetesVersion(version)
+		if err != nil || parsed != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", version, expected, parsed, err)
+		}
+	}
+	for _, version := range []string{"", "1", "latest", "1.x"} {
+		if _, err := kubelint.ParseKubernetesVersion(version); err == nil {
+			t.Errorf("Expected %q not to parse", version)
+		}
+	}
+}
+
+func TestRemovedAPIVersions(t *testing.T) {
+	for _, test := range []struct {
+		options  kubelint.RemovedAPIVersionsOptions
+		expected []string
+	}{
+		{kubelint.RemovedAPIVersionsOptions{TargetVersion: kubelint.MustParseKubernetesVersion("1.15")}, nil},
+		{kubelint.RemovedAPIVersionsOptions{TargetVersion: kubelint.MustParseKubernetesVersion("1.16")}, []string{
+			"Deployment pear uses extensions/v1beta1, which was removed in Kubernetes 1.16, migrate to apps/v1",
+		}},
+		{kubelint.RemovedAPIVersionsOptions{TargetVersion: kubelint.MustParseKubernetesVersion("1.22")}, []string{
+			"Deployment pear uses extensions/v1beta1, which was removed in Kubernetes 1.16, migrate to apps/v1",
+			"RoleBinding pear-viewer uses rbac.authorization.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to rbac.authorization.k8s.io/v1",
+			"TokenReview check uses authentication.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to authentication.k8s.io/v1",
+		}},
+		{kubelint.DefaultRemovedAPIVersionsOptions, []string{
+			"Deployment pear uses extensions/v1beta1, which was removed in Kubernetes 1.16, migrate to apps/v1",
+			"RoleBinding pear-viewer uses rbac.authorization.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to rbac.authorization.k8s.io/v1",
+			"CronJob prune uses batch/v1beta1, which was removed in Kubernetes 1.25, migrate to batch/v1",
+			"TokenReview check uses authentication.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to authentication.k8s.io/v1",
+		}},
+	} {
+		linter := kubelint.NewDefaultLinter()
+		linter.AddInterdependentRule(kubelint.NewInterdependentRemovedAPIVersionsRule(test.options))
+		results, errs := linter.LintBytes([]byte(deprecatedUnit), "FAKE.yaml")
+		for _, err := range supportedErrors(errs) {
+			t.Error(err)
+		}
+		var messages []string
+		for _, result := range results {
+			messages = append(messages, result.Message)
+		}
+		if strings.Join(messages, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Expected %v for %+v, got %v", test.expected, test.options, messages)
+		}
+	}
+}
+
+func TestDeprecatedAPIVersions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	options := kubelint.RemovedAPIVersionsOptions{TargetVersion: kubelint.MustParseKubernetesVersion("1.22")}
+	linter.AddInterdependentRule(
+		kubelint.NewInterdependentRemovedAPIVersionsRule(options),
+		kubelint.NewInterdependentDeprecatedAPIVersionsRule(options),
+	)
+	results, errs := linter.LintBytes([]byte(deprecatedUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	// what's removed is an error, but what's only deprecated is a warning
+	expected := map[string]log.Level{
+		"Deployment pear uses extensions/v1beta1, which was removed in Kubernetes 1.16, migrate to apps/v1":                                             log.ErrorLevel,
+		"RoleBinding pear-viewer uses rbac.authorization.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to rbac.authorization.k8s.io/v1": log.ErrorLevel,
+		"CronJob prune uses batch/v1beta1, which was deprecated in Kubernetes 1.21 and will be removed in 1.25, migrate to batch/v1":                    log.WarnLevel,
+		"TokenReview check uses authentication.k8s.io/v1beta1, which was removed in Kubernetes 1.22, migrate to authentication.k8s.io/v1":               log.ErrorLevel,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), describeResults(results))
+	}
+	for _, result := range results {
+		if level, found := expected[result.Message]; !found || level != result.Level {
+			t.Errorf("Didn't expect %s at %s", result.Message, result.Level)
+		}
+	}
+}
@@ -60,7 +60,8 @@ func main() {
 		kubelint.V1_SERVICE_NAME_VALID_DNS,
 	)
 	filepaths := []string{"../examples/example_yamls/deployment_invalid_user_group_ids.yaml"}
-	results, errors := linter.Lint(filepaths...)
+	session := linter.NewSession()
+	results, errors := session.Lint(filepaths...)
 	logger := log.New()
 	for _, err := range errors {
 		logger.Error(err)
@@ -76,7 +77,7 @@ func main() {
 
 	// write out the report if they want it!
 	if Fix {
-		resources, fixDescriptions := linter.ApplyFixes()
+		resources, fixDescriptions := session.ApplyFixes()
 		byteRepresentation, errs := kubelint.Write(resources...)
 		if len(errs) != 0 {
 			for err := range errs {
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestDuplicateResourcesAcrossFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubelint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: orchard
+`,
+		"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+`,
+		"more.yaml": `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: apple
+  namespace: orchard
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: apple
+  namespace: default
+`,
+	}
+	var filepaths []string
+	for _, name := range []string{"namespace.yaml", "service.yaml", "more.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filepaths = append(filepaths, path)
+	}
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_NO_DUPLICATE_RESOURCES,
+		kubelint.INTERDEPENDENT_SAME_NAME_DIFFERENT_NAMESPACE,
+	)
+	results, errs := linter.Lint(filepaths...)
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %v", describeResults(results))
+	}
+	duplicates := results[0]
+	if duplicates.Message != "Service orchard/pear is declared 2 times" {
+		t.Errorf("Unexpected message %q", duplicates.Message)
+	}
+	if len(duplicates.Resources) != 2 ||
+		duplicates.Resources[0].Filepath != filepaths[1] || duplicates.Resources[0].LineNumber != 1 ||
+		duplicates.Resources[1].Filepath != filepaths[2] || duplicates.Resources[1].LineNumber != 7 {
+		for _, resource := range duplicates.Resources {
+			t.Logf("%s:%d", resource.Filepath, resource.LineNumber)
+		}
+		t.Errorf("Expected the result to have the location of both services")
+	}
+	namespaces := results[1]
+	if namespaces.Level != log.WarnLevel || len(namespaces.Resources) != 2 || namespaces.Resources[0].Resource.Object.GetName() != "apple" {
+		t.Errorf("Expected a warning about both apple service accounts, got %v", describeResults([]*kubelint.Result{namespaces}))
+	}
+}
+
+func TestSameNameDifferentNamespaceListsEachNamespaceOnce(t *testing.T) {
+	unit := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: orchard
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: orchard
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: orchard
+`
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_SAME_NAME_DIFFERENT_NAMESPACE)
+	results, errs := linter.LintBytes([]byte(unit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %v", describeResults(results))
+	}
+	expected := `The unit has one namespace, but ConfigMap settings is in namespaces "orchard", "default"`
+	if results[0].Message != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, results[0].Message)
+	}
+}
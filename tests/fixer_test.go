@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const fixerDeployment = `kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: pear
+`
+
+func TestFixUntilStableConverges(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(
+		kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE,
+		&kubelint.AppsV1DeploymentRule{
+			ID: "DEPLOYMENT_NAME_CONTAINS_APPLE",
+			Condition: func(d *appsv1.Deployment) bool {
+				return strings.Contains(d.Name, "apple")
+			},
+			Message: "A deployment's name needs to contain the string \"apple\"",
+			Level:   log.ErrorLevel,
+			Fix: func(d *appsv1.Deployment) bool {
+				d.Name += "-apple"
+				return true
+			},
+			FixDescription: func(d *appsv1.Deployment) string {
+				return "Added apple to the deployment's name"
+			},
+		},
+	)
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(fixerDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	report := session.FixUntilStable(0)
+	for _, desc := range report.Applied {
+		t.Logf("* %s\n", desc)
+	}
+	if !report.Converged {
+		t.Errorf("Expected the fixes to converge")
+	}
+	if report.Iterations != 1 {
+		t.Errorf("Expected 1 iteration, got %d", report.Iterations)
+	}
+	if len(report.Ineffective) != 0 {
+		t.Errorf("Expected no ineffective fixes, got %d", len(report.Ineffective))
+	}
+	// the namespace rule has no fix, so it should be the only thing left
+	if len(report.Results) != 1 || report.Results[0].RuleID != "APPSV1_DEPLOYMENT_WITHIN_NAMESPACE" {
+		t.Errorf("Expected only APPSV1_DEPLOYMENT_WITHIN_NAMESPACE to still fail, got %#v", report.Results)
+	}
+}
+
+func TestFixUntilStableIneffectiveFix(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(&kubelint.AppsV1DeploymentRule{
+		ID: "DEPLOYMENT_HAS_REPLICAS",
+		Condition: func(d *appsv1.Deployment) bool {
+			return d.Spec.Replicas != nil
+		},
+		Message: "A deployment should set its replicas",
+		Level:   log.ErrorLevel,
+		Fix: func(d *appsv1.Deployment) bool {
+			return true // claims to fix it, but doesn't
+		},
+	})
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(fixerDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	report := session.FixUntilStable(5)
+	if len(report.Ineffective) != 1 || report.Ineffective[0].RuleID != "DEPLOYMENT_HAS_REPLICAS" {
+		t.Errorf("Expected DEPLOYMENT_HAS_REPLICAS to be reported as an ineffective fix, got %#v", report.Ineffective)
+	}
+	if len(report.Oscillating) != 0 {
+		t.Errorf("A fix that changes nothing shouldn't be reported as oscillating")
+	}
+}
+
+func TestFixUntilStableOscillation(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(
+		&kubelint.AppsV1DeploymentRule{
+			ID: "DEPLOYMENT_LABELLED_RED",
+			Condition: func(d *appsv1.Deployment) bool {
+				return d.Labels["colour"] == "red"
+			},
+			Level: log.ErrorLevel,
+			Fix: func(d *appsv1.Deployment) bool {
+				d.Labels = map[string]string{"colour": "red"}
+				return true
+			},
+		},
+		&kubelint.AppsV1DeploymentRule{
+			ID: "DEPLOYMENT_LABELLED_BLUE",
+			Condition: func(d *appsv1.Deployment) bool {
+				return d.Labels["colour"] == "blue"
+			},
+			Level: log.ErrorLevel,
+			Fix: func(d *appsv1.Deployment) bool {
+				d.Labels = map[string]string{"colour": "blue"}
+				return true
+			},
+		},
+	)
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(fixerDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	report := session.FixUntilStable(10)
+	t.Logf("Oscillating rules: %v after %d iterations", report.Oscillating, report.Iterations)
+	if report.Converged {
+		t.Errorf("Fixes that undo each other shouldn't converge")
+	}
+	if len(report.Oscillating) != 2 {
+		t.Errorf("Expected both rules to be reported as oscillating, got %v", report.Oscillating)
+	}
+}
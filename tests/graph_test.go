@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+)
+
+const graphUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+  labels:
+    team: fruit
+spec:
+  template:
+    metadata:
+      labels:
+        app: pear
+    spec:
+      serviceAccountName: pear
+      containers:
+      - name: app
+        image: pear:1.0
+        envFrom:
+        - configMapRef:
+            name: settings
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: orchard
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pear
+  namespace: orchard
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: pear
+  maxReplicas: 3
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: pear
+`
+
+func readGraph(t *testing.T) *kubelint.ResourceGraph {
+	ydrs, errs := kubelint.ReadBytes([]byte(graphUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Fatal(err)
+	}
+	var resources []*kubelint.Resource
+	for _, ydr := range ydrs {
+		resources = append(resources, &ydr.Resource)
+	}
+	return kubelint.NewResourceGraph(resources)
+}
+
+func TestResourceGraphEdges(t *testing.T) {
+	graph := readGraph(t)
+	deployments := graph.Lookup("Deployment", "orchard", "pear")
+	if len(deployments) != 1 {
+		t.Fatalf("Expected to look up the deployment, got %d resources", len(deployments))
+	}
+	deployment := deployments[0]
+	if labelled := graph.Labelled("team", "fruit"); len(labelled) != 1 || labelled[0] != deployment {
+		t.Errorf("Expected the deployment to be indexed by its labels")
+	}
+	var incoming []string
+	for _, edge := range graph.To(deployment, kubelint.EdgeTypeAny) {
+		incoming = append(incoming, string(edge.Type)+" from "+edge.From.TypeInfo.GetKind())
+	}
+	expected := "selects from Service, scales from HorizontalPodAutoscaler, protects from PodDisruptionBudget"
+	if strings.Join(incoming, ", ") != expected {
+		t.Errorf("Expected %s, got %v", expected, incoming)
+	}
+	var outgoing []string
+	for _, edge := range graph.From(deployment, kubelint.EdgeTypeAny) {
+		outgoing = append(outgoing, string(edge.Type)+" "+edge.To.TypeInfo.GetKind()+" "+edge.Field)
+	}
+	expected = "runs-as ServiceAccount serviceAccountName, references ConfigMap containers[app].envFrom[0].configMapRef"
+	if strings.Join(outgoing, ", ") != expected {
+		t.Errorf("Expected %s, got %v", expected, outgoing)
+	}
+}
+
+func TestResourceGraphDOT(t *testing.T) {
+	var b bytes.Buffer
+	if err := readGraph(t).WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	dot := b.String()
+	for _, line := range []string{
+		`n0 [label="Deployment\norchard/pear"];`,
+		`n3 -> n0 [label="selects"];`,
+		`n4 -> n0 [label="scales"];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("Expected the DOT output to contain %s, got\n%s", line, dot)
+		}
+	}
+}
+
+func TestGraphCondition(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(&kubelint.InterdependentRule{
+		ID: "EVERY_DEPLOYMENT_HAS_A_BUDGET",
+		GraphCondition: func(graph *kubelint.ResourceGraph) (bool, []*kubelint.Resource) {
+			var unprotected []*kubelint.Resource
+			for _, deployment := range graph.Kind("Deployment") {
+				if len(graph.To(deployment, kubelint.EdgeProtects)) == 0 {
+					unprotected = append(unprotected, deployment)
+				}
+			}
+			return len(unprotected) == 0, unprotected
+		},
+		Message: "Every deployment should have a pod disruption budget",
+		Level:   log.ErrorLevel,
+	})
+	results, errs := linter.LintBytes([]byte(graphUnit+`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: apple
+  namespace: orchard
+`), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	if len(results) != 1 || len(results[0].Resources) != 1 || results[0].Resources[0].Resource.Object.GetName() != "apple" {
+		t.Errorf("Expected only apple to be reported, got %v", describeResults(results))
+	}
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const guardDeployment = `kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: pear
+`
+
+func TestPanickingConditionBecomesError(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(
+		&kubelint.AppsV1DeploymentRule{
+			ID: "FIRST_CONTAINER_NAMED_APP",
+			Condition: func(d *appsv1.Deployment) bool {
+				// forgot the prerequisite that checks there's a container
+				return d.Spec.Template.Spec.Containers[0].Name == "app"
+			},
+			Level: log.ErrorLevel,
+		},
+		kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE,
+	)
+	results, errs := linter.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	if len(errs) != 1 {
+		t.Fatalf("Expected exactly 1 error, got %v", errs)
+	}
+	var ruleErr *kubelint.RuleError
+	if !errors.As(errs[0], &ruleErr) {
+		t.Fatalf("Expected a RuleError, got %T", errs[0])
+	}
+	t.Log(ruleErr)
+	if ruleErr.RuleID != "FIRST_CONTAINER_NAMED_APP" {
+		t.Errorf("Expected the error to name the rule, got %s", ruleErr.RuleID)
+	}
+	if !strings.Contains(ruleErr.Error(), "Deployment pear") {
+		t.Errorf("Expected the error to name the resource, got %s", ruleErr.Error())
+	}
+	// the other rules should still be evaluated
+	if len(results) != 1 || results[0].RuleID != "APPSV1_DEPLOYMENT_WITHIN_NAMESPACE" {
+		t.Errorf("Expected the namespace rule to still be reported, got %#v", results)
+	}
+}
+
+func TestUnorderableRulesBecomeErrors(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	never := func(d *appsv1.Deployment) bool { return false }
+	linter.AddAppsV1DeploymentRule(
+		&kubelint.AppsV1DeploymentRule{ID: "MISSING_PREREQ", Prereqs: []kubelint.RuleID{"NOPE"}, Condition: never},
+		&kubelint.AppsV1DeploymentRule{ID: "AFTER_MISSING_PREREQ", Prereqs: []kubelint.RuleID{"MISSING_PREREQ"}, Condition: never},
+		&kubelint.AppsV1DeploymentRule{ID: "CHICKEN", Prereqs: []kubelint.RuleID{"EGG"}, Condition: never},
+		&kubelint.AppsV1DeploymentRule{ID: "EGG", Prereqs: []kubelint.RuleID{"CHICKEN"}, Condition: never},
+		kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE,
+	)
+	results, errs := linter.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	var messages []string
+	for _, err := range errs {
+		var ruleErr *kubelint.RuleError
+		if !errors.As(err, &ruleErr) {
+			t.Fatalf("Expected a RuleError, got %T", err)
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", ruleErr.RuleID, ruleErr.Err))
+	}
+	expected := []string{
+		"MISSING_PREREQ: its prerequisite NOPE isn't registered",
+		"AFTER_MISSING_PREREQ: its prerequisite MISSING_PREREQ was left out",
+		"CHICKEN: it's part of a cycle of prerequisites",
+		"EGG: it's part of a cycle of prerequisites",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+	}
+	// the rules that can be ordered should still be evaluated
+	if len(results) != 1 || results[0].RuleID != "APPSV1_DEPLOYMENT_WITHIN_NAMESPACE" {
+		t.Errorf("Expected the namespace rule to still be reported, got %v", describeResults(results))
+	}
+}
+
+func TestSlowConditionTimesOut(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.SetRuleTimeout(10 * time.Millisecond)
+	linter.AddAppsV1DeploymentRule(&kubelint.AppsV1DeploymentRule{
+		ID: "VERY_SLOW_RULE",
+		Condition: func(d *appsv1.Deployment) bool {
+			time.Sleep(time.Second)
+			return true
+		},
+	})
+	start := time.Now()
+	_, errs := linter.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Linting should have given up on the slow rule")
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "VERY_SLOW_RULE") {
+		t.Errorf("Expected a timeout error naming the rule, got %v", errs)
+	}
+}
+
+func TestFixesWaitForTimedOutCondition(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.SetRuleTimeout(10 * time.Millisecond)
+	release := make(chan struct{})
+	linter.AddAppsV1DeploymentRule(
+		&kubelint.AppsV1DeploymentRule{
+			ID: "STUCK_RULE",
+			Condition: func(d *appsv1.Deployment) bool {
+				<-release
+				return d.Spec.Replicas != nil
+			},
+		},
+		&kubelint.AppsV1DeploymentRule{
+			ID:        "REPLICAS_SET",
+			Condition: func(d *appsv1.Deployment) bool { return d.Spec.Replicas != nil },
+			Fix: func(d *appsv1.Deployment) bool {
+				replicas := int32(2)
+				d.Spec.Replicas = &replicas
+				return true
+			},
+			FixDescription: func(d *appsv1.Deployment) string { return "set the replicas" },
+		},
+	)
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "timed out") {
+		t.Fatalf("Expected the stuck rule to time out, got %v", errs)
+	}
+	// the stuck condition is still reading the deployment, so it mustn't be fixed
+	_, descriptions, errs := session.ApplyFixesContext(context.Background())
+	if len(descriptions) != 0 {
+		t.Errorf("Expected no fixes while the condition is still running, got %v", descriptions)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "STUCK_RULE") || !strings.Contains(errs[0].Error(), "still running") {
+		t.Errorf("Expected an error saying the stuck rule is still running, got %v", errs)
+	}
+	// once it returns, the fixes can go ahead
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+	_, errs = session.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	_, descriptions, errs = session.ApplyFixesContext(context.Background())
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if len(descriptions) != 1 {
+		t.Errorf("Expected the replicas to be fixed, got %v", descriptions)
+	}
+}
+
+func TestCancelledContextStopsLinting(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, errs := linter.LintBytesContext(ctx, []byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	if len(results) != 0 {
+		t.Errorf("Expected no results once the context is cancelled, got %d", len(results))
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("Expected the context's error, got %v", errs)
+	}
+}
+
+func TestPanickingFixBecomesError(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(&kubelint.AppsV1DeploymentRule{
+		ID: "REPLICAS_SET",
+		Condition: func(d *appsv1.Deployment) bool {
+			return d.Spec.Replicas != nil
+		},
+		Fix: func(d *appsv1.Deployment) bool {
+			*d.Spec.Replicas = 2 // nil dereference
+			return true
+		},
+	})
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	_, descriptions, errs := session.ApplyFixesContext(context.Background())
+	if len(descriptions) != 0 {
+		t.Errorf("A panicking fix shouldn't be reported as applied")
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "REPLICAS_SET") {
+		t.Errorf("Expected an error naming the rule, got %v", errs)
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const nginxDigest = "sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+
+func TestParseImageReference(t *testing.T) {
+	for image, expected := range map[string]kubelint.ImageReference{
+		"nginx":                        {Registry: "docker.io", Repository: "library/nginx"},
+		"nginx:1.17":                   {Registry: "docker.io", Repository: "library/nginx", Tag: "1.17"},
+		"bitnami/redis:6.0":            {Registry: "docker.io", Repository: "bitnami/redis", Tag: "6.0"},
+		"localhost:5000/pear":          {Registry: "localhost:5000", Repository: "pear"},
+		"quay.io/coreos/etcd:v3.4":     {Registry: "quay.io", Repository: "coreos/etcd", Tag: "v3.4"},
+		"nginx@" + nginxDigest:         {Registry: "docker.io", Repository: "library/nginx", Digest: nginxDigest},
+		"nginx:1.17@" + nginxDigest:    {Registry: "docker.io", Repository: "library/nginx", Tag: "1.17", Digest: nginxDigest},
+		"gcr.io/pear/app:latest":       {Registry: "gcr.io", Repository: "pear/app", Tag: "latest"},
+		"registry.local:443/a/b/c:1.0": {Registry: "registry.local:443", Repository: "a/b/c", Tag: "1.0"},
+	} {
+		reference, err := kubelint.ParseImageReference(image)
+		if err != nil {
+			t.Errorf("%s: %s", image, err)
+			continue
+		}
+		if *reference != expected {
+			t.Errorf("%s: expected %+v, got %+v", image, expected, *reference)
+		}
+	}
+	for _, image := range []string{"", "Nginx", "nginx:", "nginx@sha256:abc", "nginx:-1", "bad_host.io:x/pear"} {
+		if _, err := kubelint.ParseImageReference(image); err == nil {
+			t.Errorf("Expected %q not to parse", image)
+		}
+	}
+}
+
+const imagesUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      containers:
+      - name: latest
+        image: nginx
+      - name: tagged
+        image: quay.io/coreos/etcd:v3.4
+      - name: pinned
+        image: nginx@` + nginxDigest + `
+        imagePullPolicy: Always
+`
+
+func TestImageRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(
+		kubelint.V1_CONTAINER_IMAGE_NOT_LATEST,
+		kubelint.V1_CONTAINER_IMAGE_DIGEST_PINNED,
+		kubelint.V1_CONTAINER_IMAGE_PULL_POLICY_CONSISTENT,
+		kubelint.NewV1ContainerValidImageRule(kubelint.ImageOptions{
+			DeniedRegistries: []string{"docker.io"},
+			DeniedImages:     []*regexp.Regexp{regexp.MustCompile(`/etcd:v3\.4$`)},
+		}),
+	)
+	results, errs := linter.LintBytes([]byte(imagesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"V1_CONTAINER_IMAGE_NOT_LATEST latest: The container's image should have a tag other than latest, or be pinned to a digest",
+		"V1_CONTAINER_IMAGE_DIGEST_PINNED latest: The container's image should be pinned to a digest",
+		"V1_CONTAINER_IMAGE_DIGEST_PINNED tagged: The container's image should be pinned to a digest",
+		"V1_CONTAINER_IMAGE_PULL_POLICY_CONSISTENT pinned: The container's imagePullPolicy should be Always for a latest image, and shouldn't be Always for an image pinned to a digest",
+		"V1_CONTAINER_VALID_IMAGE latest: The container's image was not from the set of allowed images",
+		"V1_CONTAINER_VALID_IMAGE tagged: The container's image was not from the set of allowed images",
+		"V1_CONTAINER_VALID_IMAGE pinned: The container's image was not from the set of allowed images",
+	)
+}
+
+func TestPinLatestImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubelint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mapping := filepath.Join(dir, "digests.yaml")
+	if err := ioutil.WriteFile(mapping, []byte("docker.io/library/nginx:latest: "+nginxDigest+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	digests, err := kubelint.ReadImageDigests(mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.NewV1ContainerImageNotLatestRule(digests))
+	session := linter.NewSession()
+	if _, errs := session.LintBytes([]byte(imagesUnit), "FAKE.yaml"); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	resources, fixes := session.ApplyFixes()
+	if len(fixes) != 1 || fixes[0] != "Pinned container latest's image to nginx@"+nginxDigest {
+		t.Errorf("Expected the latest image to be pinned, got %v", fixes)
+	}
+	deployment := resources[0].Object.(*appsv1.Deployment)
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "nginx@"+nginxDigest {
+		t.Errorf("Expected the image to be rewritten, got %s", image)
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+const ingressUnit = `apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pear-tls
+  namespace: orchard
+type: kubernetes.io/tls
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  backend:
+    serviceName: pear
+    servicePort: http
+  tls:
+  - secretName: pear-tls
+  - hosts: [default.example.com]
+  rules:
+  - host: pear.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: pear
+          servicePort: 80
+      - path: /admin
+        backend:
+          serviceName: pear
+          servicePort: 8080
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  tls:
+  - secretName: apple-tls
+  rules:
+  - http:
+      paths:
+      - backend:
+          serviceName: apple
+          servicePort: http
+`
+
+func TestIngressBackendsResolve(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_INGRESS_BACKEND_EXISTS,
+		kubelint.INTERDEPENDENT_INGRESS_TLS_SECRET_EXISTS,
+	)
+	results, errs := linter.LintBytes([]byte(ingressUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	expected := []string{
+		`INTERDEPENDENT_INGRESS_BACKEND_EXISTS: Ingress pear: rules[0].http.paths[1].backend refers to Service "pear", which has no port 8080`,
+		`INTERDEPENDENT_INGRESS_BACKEND_EXISTS: Ingress apple: rules[0].http.paths[0].backend refers to port http of Service "apple", which isn't in the unit`,
+		`INTERDEPENDENT_INGRESS_TLS_SECRET_EXISTS: Ingress apple: TLS secretName refers to Secret "apple-tls", which isn't in the unit`,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), describeResults(results))
+	}
+	for i, result := range results {
+		if got := fmt.Sprintf("%s: %s", result.RuleID, result.Message); got != expected[i] {
+			t.Errorf("Expected\n%s\ngot\n%s", expected[i], got)
+		}
+	}
+}
+
+const ingressRulesUnit = `apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: old
+  namespace: orchard
+  annotations:
+    kubernetes.io/ingress.class: nginx
+spec:
+  tls:
+  - hosts: ["*.example.com"]
+    secretName: example-tls
+  rules:
+  - host: pear.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: pear
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: new
+  namespace: orchard
+spec:
+  tls:
+  - hosts: ["*.example.com"]
+    secretName: example-tls
+  rules:
+  - host: "*.example.com"
+    http:
+      paths:
+      - backend:
+          serviceName: pear
+          servicePort: 80
+  - host: apple.orchard.example.com
+    http:
+      paths:
+      - backend:
+          serviceName: apple
+          servicePort: 80
+`
+
+func TestIngressRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1Beta1ExtensionsIngressRule(
+		kubelint.V1BETA1_EXTENSIONS_INGRESS_TLS_COVERS_HOSTS,
+		kubelint.V1BETA1_EXTENSIONS_INGRESS_NO_WILDCARD_HOSTS,
+		kubelint.V1BETA1_EXTENSIONS_INGRESS_EXISTS_CLASS_ANNOTATION,
+	)
+	linter.AddNetworkingV1beta1IngressRule(
+		kubelint.NETWORKINGV1BETA1_INGRESS_TLS_COVERS_HOSTS,
+		kubelint.NETWORKINGV1BETA1_INGRESS_NO_WILDCARD_HOSTS,
+		kubelint.NETWORKINGV1BETA1_INGRESS_EXISTS_CLASS_ANNOTATION,
+	)
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_INGRESS_NETWORKING_API)
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(ingressRulesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"NETWORKINGV1BETA1_INGRESS_TLS_COVERS_HOSTS new: An ingress should serve every host in its rules over TLS", // the wildcard certificate is only one level deep
+		"NETWORKINGV1BETA1_INGRESS_NO_WILDCARD_HOSTS new: An ingress shouldn't have rules for wildcard hosts, since it takes traffic for subdomains nobody has claimed",
+		"NETWORKINGV1BETA1_INGRESS_EXISTS_CLASS_ANNOTATION new: An ingress should have a kubernetes.io/ingress.class annotation, otherwise every ingress controller in the cluster may serve it",
+		"INTERDEPENDENT_INGRESS_NETWORKING_API old: Ingress old uses extensions/v1beta1, which is deprecated in favour of networking.k8s.io/v1beta1",
+	)
+
+	resources, fixes := session.ApplyFixes()
+	if len(fixes) != 1 || fixes[0] != "Converted Ingress old to networking.k8s.io/v1beta1" {
+		t.Errorf("Expected the old ingress to be converted, got %v", fixes)
+	}
+	converted, ok := resources[0].Object.(*networkingV1beta1.Ingress)
+	if !ok {
+		t.Fatalf("Expected a networking.k8s.io/v1beta1 Ingress, got %T", resources[0].Object)
+	}
+	if converted.Annotations["kubernetes.io/ingress.class"] != "nginx" || converted.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName != "pear" {
+		t.Errorf("Expected the metadata and spec to be carried over, got %+v", converted)
+	}
+	if typeInfo := resources[0].TypeInfo; typeInfo.GetAPIVersion() != "networking.k8s.io/v1beta1" || typeInfo.GetKind() != "Ingress" {
+		t.Errorf("Expected the converted ingress to have its new API version, got %s %s", typeInfo.GetAPIVersion(), typeInfo.GetKind())
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestInterdependentPrerequisites(t *testing.T) {
+	evaluations := 0
+	dependent := &kubelint.InterdependentRule{
+		ID:      "EVERYTHING_NAMESPACED",
+		Prereqs: []kubelint.RuleID{"INTERDEPENDENT_ONE_NAMESPACE"},
+		Condition: func(resources []*kubelint.Resource) (bool, []*kubelint.Resource) {
+			evaluations++
+			return true, nil
+		},
+		Message: "Everything should be namespaced",
+		Level:   log.ErrorLevel,
+	}
+	linter := kubelint.NewDefaultLinter()
+	// the dependent rule is added first, so it's only evaluated after its prerequisite if the prerequisites are respected
+	linter.AddInterdependentRule(dependent, kubelint.INTERDEPENDENT_ONE_NAMESPACE)
+
+	results, errs := linter.LintBytes([]byte(guardDeployment), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if evaluations != 0 {
+		t.Errorf("The rule shouldn't be evaluated when its prerequisite fails")
+	}
+	if len(results) != 2 || results[0].RuleID != "INTERDEPENDENT_ONE_NAMESPACE" || results[1].RuleID != "EVERYTHING_NAMESPACED" {
+		t.Errorf("Expected the prerequisite and the rule depending on it to be reported, got %v", describeResults(results))
+	}
+
+	results, errs = linter.LintBytes([]byte(guardDeployment+`---
+kind: Namespace
+apiVersion: v1
+metadata:
+  name: orchard
+`), "FAKE_DEPLOYMENT.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if evaluations != 1 {
+		t.Errorf("Expected the rule to be evaluated exactly once, got %d", evaluations)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %v", describeResults(results))
+	}
+}
@@ -26,14 +26,15 @@ apiVersion: v1
 metadata:
   name: mynamespace
 `)
-	results, errors := linter.LintBytes(unit, "FAKE.yaml")
+	session := linter.NewSession()
+	results, errors := session.LintBytes(unit, "FAKE.yaml")
 	for _, err := range errors {
 		t.Error(err)
 	}
 	for _, result := range results {
 		t.Logf("%s: %s\n", result.Level, result.Message)
 	}
-	resources, descriptions := linter.ApplyFixes()
+	resources, descriptions := session.ApplyFixes()
 	for _, fix := range descriptions {
 		t.Logf("* %s\n", fix)
 	}
@@ -29,7 +29,8 @@ func TestLinterBasic(t *testing.T) {
 				return fmt.Sprintf("Changed deployment's name to %s", d.Name)
 			},
 		})
-	results, errs := linter.LintBytes([]byte(`kind: Deployment
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(`kind: Deployment
 apiVersion: apps/v1
 metadata:
   name: pear
@@ -41,7 +42,7 @@ metadata:
 	for _, err := range errs {
 		t.Error(err)
 	}
-	resources, fixDescriptions := linter.ApplyFixes()
+	resources, fixDescriptions := session.ApplyFixes()
 	for _, resource := range resources {
 		bytes, _ := kubelint.Write(resource)
 		t.Log(string(bytes))
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const networkPolicyUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      labels:
+        app: pear
+        tier: web
+    spec:
+      containers:
+      - name: app
+        image: pear:1.0
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      labels:
+        app: apple
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: app
+        image: apple:1.0
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: web
+  namespace: orchard
+spec:
+  podSelector:
+    matchExpressions:
+    - key: tier
+      operator: In
+      values: [web]
+  policyTypes: [Ingress, Egress]
+---
+apiVersion: extensions/v1beta1
+kind: NetworkPolicy
+metadata:
+  name: default-deny-ingress
+  namespace: orchard
+spec:
+  podSelector: {}
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: banana
+  namespace: orchard
+spec:
+  podSelector:
+    matchLabels:
+      app: banana
+`
+
+func TestNetworkPoliciesSelectWorkloads(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_WORKLOAD_INGRESS_POLICY,
+		kubelint.INTERDEPENDENT_WORKLOAD_EGRESS_POLICY,
+		kubelint.INTERDEPENDENT_NETWORK_POLICY_SELECTS_WORKLOAD,
+	)
+	results, errs := linter.LintBytes([]byte(networkPolicyUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	expected := map[kubelint.RuleID]string{
+		"INTERDEPENDENT_WORKLOAD_EGRESS_POLICY":          "No network policy restricts egress from the pods of Job apple",
+		"INTERDEPENDENT_NETWORK_POLICY_SELECTS_WORKLOAD": "The podSelector of NetworkPolicy banana doesn't select the pods of any workload in its namespace",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), describeResults(results))
+	}
+	for _, result := range results {
+		if result.Message != expected[result.RuleID] {
+			t.Errorf("Expected %s to report %q, got %q", result.RuleID, expected[result.RuleID], result.Message)
+		}
+	}
+}
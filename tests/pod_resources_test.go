@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const resourcesUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: pear:1.0
+        resources:
+          requests:
+            cpu: "3"
+            memory: 1Gi
+          limits:
+            cpu: "3"
+            memory: 1Gi
+      containers:
+      - name: app
+        image: pear:1.0
+        resources:
+          requests:
+            cpu: "1"
+            memory: 512Mi
+          limits:
+            cpu: "2"
+            memory: 2Gi
+            ephemeral-storage: 1Gi
+      - name: sidecar
+        image: pear:1.0
+        resources:
+          limits:
+            cpu: 500m
+            memory: 128Mi
+`
+
+func TestContainerResourceRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddWorkloadV1ContainerRule(
+		kubelint.V1_CONTAINER_MEMORY_LIMIT_EQUALS_REQUEST,
+		kubelint.V1_CONTAINER_LIMIT_REQUEST_RATIO,
+		kubelint.NewV1ContainerEphemeralStorageRule(kubelint.EphemeralStorageOptions{MaxLimit: resource.MustParse("500Mi")}),
+	)
+	results, errs := linter.LintBytes([]byte(resourcesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"V1_CONTAINER_MEMORY_LIMIT_EQUALS_REQUEST app: The container's memory limit should be set, and its memory request should be the same",    // a limit of 2Gi and a request of 512Mi
+		"V1_CONTAINER_LIMIT_REQUEST_RATIO app: The container's limits should be at most 4 times its cpu request, and 2 times its memory request", // 4 times its memory request
+		"V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT migrate: The container should have an ephemeral-storage limit of at most 500Mi",
+		"V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT app: The container should have an ephemeral-storage limit of at most 500Mi", // over the cap
+		"V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT sidecar: The container should have an ephemeral-storage limit of at most 500Mi",
+	)
+}
+
+func TestContainerRulesSkipInitContainersUnlessAskedTo(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.NewV1ContainerEphemeralStorageRule(kubelint.EphemeralStorageOptions{MaxLimit: resource.MustParse("500Mi")}))
+	results, errs := linter.LintBytes([]byte(resourcesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	// the migrate init container isn't checked
+	expectResults(t, results,
+		"V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT app: The container should have an ephemeral-storage limit of at most 500Mi",
+		"V1_CONTAINER_EPHEMERAL_STORAGE_LIMIT sidecar: The container should have an ephemeral-storage limit of at most 500Mi",
+	)
+}
+
+func TestPodResourceTotals(t *testing.T) {
+	// the containers request 1.5 CPU together, but the init container requests 3 on its own
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.NewV1PodSpecResourceTotalsRule(kubelint.PodResourceTotalsOptions{
+		MaxRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+	}))
+	results, errs := linter.LintBytes([]byte(resourcesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results, "V1_PODSPEC_RESOURCE_TOTALS pear: The pod's containers should request at most 2 cpu in total")
+
+	// the containers' memory limits add up to 2176Mi
+	linter = kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.NewV1PodSpecResourceTotalsRule(kubelint.PodResourceTotalsOptions{
+		MaxLimits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2176Mi")},
+	}))
+	results, errs = linter.LintBytes([]byte(resourcesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results)
+}
+
+func TestPodQOSClass(t *testing.T) {
+	guaranteed := v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}
+	burstable := v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}
+	for _, test := range []struct {
+		containers []v1.Container
+		expected   v1.PodQOSClass
+	}{
+		{[]v1.Container{{Name: "a"}}, v1.PodQOSBestEffort},
+		{[]v1.Container{{Name: "a", Resources: v1.ResourceRequirements{Limits: guaranteed}}}, v1.PodQOSGuaranteed},
+		{[]v1.Container{{Name: "a", Resources: v1.ResourceRequirements{Limits: guaranteed, Requests: guaranteed}}}, v1.PodQOSGuaranteed},
+		{[]v1.Container{{Name: "a", Resources: v1.ResourceRequirements{Limits: guaranteed, Requests: burstable}}}, v1.PodQOSBurstable},
+		{[]v1.Container{{Name: "a", Resources: v1.ResourceRequirements{Limits: guaranteed}}, {Name: "b"}}, v1.PodQOSBurstable},
+	} {
+		if class := kubelint.PodQOSClass(&v1.PodSpec{Containers: test.containers}); class != test.expected {
+			t.Errorf("Expected %s, got %s for %+v", test.expected, class, test.containers)
+		}
+	}
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.NewV1PodSpecQOSClassRule(kubelint.QOSClassOptions{Allowed: []v1.PodQOSClass{v1.PodQOSGuaranteed}}))
+	results, errs := linter.LintBytes([]byte(resourcesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results, "V1_PODSPEC_QOS_CLASS pear: The pod's quality of service class should be Guaranteed")
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const probesUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: pear:1.0
+        ports:
+        - name: http
+          containerPort: 8080
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: http
+          initialDelaySeconds: 90
+          failureThreshold: 1
+        readinessProbe:
+          httpGet:
+            path: /healthz
+            port: http
+      - name: database
+        image: postgres:12
+        ports:
+        - containerPort: 5432
+        livenessProbe:
+          exec:
+            command: [pg_isready]
+          timeoutSeconds: 10
+        readinessProbe:
+          tcpSocket:
+            port: 5433
+      - name: proxy
+        image: envoy:1.14
+        livenessProbe:
+          tcpSocket:
+            port: 9901
+        startupProbe:
+          tcpSocket:
+            port: 9901
+`
+
+func TestProbeRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(
+		kubelint.V1_CONTAINER_EXISTS_LIVENESS,
+		kubelint.V1_CONTAINER_EXISTS_READINESS,
+		kubelint.V1_CONTAINER_LIVENESS_READINESS_NONMATCHING,
+		kubelint.V1_CONTAINER_PROBE_PORTS_EXIST,
+		kubelint.V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS,
+		kubelint.V1_CONTAINER_PROBE_TIMINGS,
+	)
+	results, errs := linter.LintBytes([]byte(probesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"V1_CONTAINER_EXISTS_READINESS proxy: Expected declaration of readiness probe for the container (readinessProbe) with an httpGet, tcpSocket or exec handler",
+		"V1_CONTAINER_LIVENESS_READINESS_NONMATCHING proxy: It's recommended that the readiness and liveness probes don't check the same thing, so a container that's busy isn't restarted", // its prerequisite failed
+		"V1_CONTAINER_LIVENESS_READINESS_NONMATCHING app: It's recommended that the readiness and liveness probes don't check the same thing, so a container that's busy isn't restarted",
+		"V1_CONTAINER_PROBE_PORTS_EXIST database: The container's probes should connect to ports in its ports list (containerPort or name)",
+		"V1_CONTAINER_PROBE_PORTS_EXIST proxy: The container's probes should connect to ports in its ports list (containerPort or name)",
+		"V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS app: The container's liveness probe waits 30 seconds or more to start, it should have a startupProbe instead",
+		"V1_CONTAINER_PROBE_TIMINGS app: The container's probes should have an initialDelaySeconds of at most 60, a timeoutSeconds of at most 10 (and less than periodSeconds), and its liveness probe should have a failureThreshold of at least 3",      // too long a delay, and a failure threshold of 1
+		"V1_CONTAINER_PROBE_TIMINGS database: The container's probes should have an initialDelaySeconds of at most 60, a timeoutSeconds of at most 10 (and less than periodSeconds), and its liveness probe should have a failureThreshold of at least 3", // a timeout as long as the period
+	)
+}
+
+func TestDeploymentProbeRulesCheckEveryContainer(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.V1_PODSPEC_NON_ZERO_CONTAINERS)
+	linter.AddAppsV1DeploymentRule(
+		kubelint.APPSV1_DEPLOYMENT_CONTAINER_EXISTS_LIVENESS,
+		kubelint.APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS,
+		kubelint.APPSV1_DEPLOYMENT_LIVENESS_READINESS_NONMATCHING,
+	)
+	results, errs := linter.LintBytes([]byte(probesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	// the exec and TCP probes count, but the proxy has no readiness probe
+	expectResults(t, results,
+		"APPSV1_DEPLOYMENT_CONTAINER_EXISTS_READINESS pear: Expected declaration of readiness probe for the container (readinessProbe)",
+		"APPSV1_DEPLOYMENT_LIVENESS_READINESS_NONMATCHING pear: It's recommended that the readiness and liveness probe endpoints don't match",
+	)
+}
+
+func TestProbeRulesDontCheckZeroOptions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(
+		kubelint.NewV1ContainerStartupProbeRule(kubelint.StartupProbeOptions{}),
+		kubelint.NewV1ContainerProbeTimingsRule(kubelint.ProbeTimingsOptions{MaxTimeoutSeconds: 5}),
+	)
+	results, errs := linter.LintBytes([]byte(probesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	// a zero slow start falls back to the default, and the app's delay and failure threshold aren't checked
+	expectResults(t, results,
+		"V1_CONTAINER_STARTUP_PROBE_FOR_SLOW_STARTERS app: The container's liveness probe waits 30 seconds or more to start, it should have a startupProbe instead",
+		"V1_CONTAINER_PROBE_TIMINGS database: The container's probes should have a timeoutSeconds of at most 5 (and less than periodSeconds)",
+	)
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const profileUnit = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      annotations:
+        container.apparmor.security.beta.kubernetes.io/app: unconfined
+    spec:
+      hostNetwork: true
+      securityContext:
+        runAsNonRoot: true
+      initContainers:
+      - name: setup
+        image: pear:1.0
+        securityContext:
+          privileged: true
+      containers:
+      - name: app
+        image: pear:1.0
+        securityContext:
+          capabilities:
+            add: [SYS_ADMIN]
+      - name: sidecar
+        image: pear:1.0
+        ports:
+        - containerPort: 80
+          hostPort: 80
+`
+
+func lintProfile(t *testing.T, profile *kubelint.Profile) []string {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddProfile(profile)
+	results, errs := linter.LintBytes([]byte(profileUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	var described []string
+	for _, result := range results {
+		described = append(described, fmt.Sprintf("%s %s [%s]", result.RuleID, result.Container, result.Control))
+	}
+	return described
+}
+
+func TestBaselineProfile(t *testing.T) {
+	expected := []string{
+		"GENERIC_PSS_APPARMOR  [AppArmor]",
+		"V1_PODSPEC_PSS_HOST_NAMESPACES  [Host Namespaces]",
+		"V1_CONTAINER_PSS_PRIVILEGED setup [Privileged Containers]",
+		"V1_CONTAINER_PSS_BASELINE_CAPABILITIES app [Capabilities]",
+		"V1_CONTAINER_PSS_HOST_PORTS sidecar [Host Ports]",
+	}
+	described := lintProfile(t, kubelint.ProfileBaseline)
+	if fmt.Sprint(described) != fmt.Sprint(expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, described)
+	}
+}
+
+func TestRestrictedProfile(t *testing.T) {
+	described := lintProfile(t, kubelint.ProfileRestricted)
+	counts := make(map[string]int)
+	for _, result := range described {
+		counts[result]++
+	}
+	for _, container := range []string{"setup", "app", "sidecar"} {
+		for _, expected := range []string{
+			"V1_CONTAINER_PSS_ALLOW_PRIVILEGE_ESCALATION " + container + " [Privilege Escalation]",
+			"V1_CONTAINER_PSS_DROP_ALL_CAPABILITIES " + container + " [Capabilities]",
+		} {
+			if counts[expected] != 1 {
+				t.Errorf("Expected %s once, got %v", expected, described)
+			}
+		}
+	}
+	for _, expected := range []string{
+		"GENERIC_PSS_SECCOMP_RESTRICTED  [Seccomp]",
+		"V1_CONTAINER_PSS_RESTRICTED_CAPABILITIES app [Capabilities]",
+	} {
+		if counts[expected] != 1 {
+			t.Errorf("Expected %s once, got %v", expected, described)
+		}
+	}
+	if counts["V1_PODSPEC_PSS_RUN_AS_NON_ROOT  [Running as Non-root]"] != 0 {
+		t.Errorf("Expected the pod's runAsNonRoot to cover its containers, got %v", described)
+	}
+}
+
+func TestPrivilegedProfile(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddProfile(kubelint.ProfilePrivileged)
+	results, errs := linter.LintBytes([]byte(profileUnit), "FAKE.yaml")
+	if len(results) != 0 {
+		t.Errorf("Expected the privileged profile not to check anything, got %v", describeResults(results))
+	}
+	// with no rules for its pods, nothing has considered the StatefulSet
+	if len(errs) != 1 || errs[0].Error() != "Resources of type *v1.StatefulSet have not been considered by the linter" {
+		t.Errorf("Expected the StatefulSet to be reported as not considered, got %v", errs)
+	}
+}
+
+func TestContainerRulesCheckEveryContainer(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddWorkloadV1ContainerRule(kubelint.V1_CONTAINER_EXISTS_SECURITY_CONTEXT, kubelint.V1_CONTAINER_PRIVILEGED_FALSE)
+	results, errs := linter.LintBytes([]byte(profileUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	var described []string
+	for _, result := range results {
+		described = append(described, string(result.RuleID)+" "+result.Container)
+	}
+	expected := []string{
+		"V1_CONTAINER_EXISTS_SECURITY_CONTEXT sidecar",
+		"V1_CONTAINER_PRIVILEGED_FALSE sidecar", // reported because its prerequisite failed
+		"V1_CONTAINER_PRIVILEGED_FALSE setup",
+		"V1_CONTAINER_PRIVILEGED_FALSE app",
+	}
+	if fmt.Sprint(described) != fmt.Sprint(expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, described)
+	}
+}
+
+func TestContainerRulesOnlyCheckDeploymentsUnlessAskedTo(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.V1_PODSPEC_PSS_HOST_NAMESPACES)
+	linter.AddV1ContainerRule(kubelint.V1_CONTAINER_EXISTS_SECURITY_CONTEXT, kubelint.V1_CONTAINER_PRIVILEGED_FALSE)
+	results, errs := linter.LintBytes([]byte(profileUnit), "FAKE.yaml")
+	if len(results) != 0 {
+		t.Errorf("Expected the StatefulSet not to be checked, got %v", describeResults(results))
+	}
+	if len(errs) != 1 || errs[0].Error() != "Resources of type *v1.StatefulSet have not been considered by the linter" {
+		t.Errorf("Expected the StatefulSet to be reported as not considered, got %v", errs)
+	}
+	// once there's a rule for every workload's pods, the StatefulSet has been considered
+	linter.AddWorkloadV1PodSpecRule(kubelint.V1_PODSPEC_PSS_HOST_NAMESPACES)
+	results, errs = linter.LintBytes([]byte(profileUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results, "V1_PODSPEC_PSS_HOST_NAMESPACES pear: "+kubelint.V1_PODSPEC_PSS_HOST_NAMESPACES.Message)
+}
@@ -0,0 +1,284 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const rbacUnit = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pear
+  namespace: orchard
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: pear-reader
+  namespace: orchard
+rules:
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1beta1
+kind: RoleBinding
+metadata:
+  name: pear-reader
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: pear-reader
+subjects:
+- kind: ServiceAccount
+  name: pear
+---
+apiVersion: rbac.authorization.k8s.io/v1beta1
+kind: RoleBinding
+metadata:
+  name: pear-viewer
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: default
+---
+apiVersion: rbac.authorization.k8s.io/v1beta1
+kind: RoleBinding
+metadata:
+  name: missing-role
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: apple-reader
+subjects:
+- kind: ServiceAccount
+  name: pear
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: missing-account
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: system:auth-delegator
+subjects:
+- kind: ServiceAccount
+  name: apple
+  namespace: orchard
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      serviceAccountName: pear
+      containers:
+      - name: app
+        image: pear:1.0
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  template:
+    spec:
+      serviceAccountName: apple
+      containers:
+      - name: app
+        image: apple:1.0
+`
+
+// supportedErrors leaves out the errors about resources the linter has no rules for,
+// since interdependent rules can still see those resources.
+func supportedErrors(errs []error) []error {
+	var supported []error
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "have not been considered by the linter") {
+			supported = append(supported, err)
+		}
+	}
+	return supported
+}
+
+func TestRBACReferencesResolve(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS,
+		kubelint.INTERDEPENDENT_ROLE_BINDING_SERVICE_ACCOUNT_EXISTS,
+		kubelint.INTERDEPENDENT_WORKLOAD_SERVICE_ACCOUNT_EXISTS,
+	)
+	results, errs := linter.LintBytes([]byte(rbacUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	expected := map[kubelint.RuleID]string{
+		"INTERDEPENDENT_ROLE_BINDING_ROLE_EXISTS":            "missing-role",
+		"INTERDEPENDENT_ROLE_BINDING_SERVICE_ACCOUNT_EXISTS": "missing-account",
+		"INTERDEPENDENT_WORKLOAD_SERVICE_ACCOUNT_EXISTS":     "apple",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for _, result := range results {
+		if len(result.Resources) != 1 || result.Resources[0].Resource.Object.GetName() != expected[result.RuleID] {
+			t.Errorf("Expected %s to only report %s, got %v", result.RuleID, expected[result.RuleID], describeResults([]*kubelint.Result{result}))
+		}
+	}
+}
+
+const privilegesUnit = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pear
+  namespace: orchard
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: apple
+  namespace: orchard
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: secret-reader
+  namespace: orchard
+rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  resourceNames: ["database"]
+  verbs: ["get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: debugger
+rules:
+- apiGroups: [""]
+  resources: ["*/exec"]
+  verbs: ["create"]
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["clusterroles"]
+  verbs: ["bind"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: pear-secret-reader
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: secret-reader
+subjects:
+- kind: ServiceAccount
+  name: pear
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: orchard-debugger
+  namespace: orchard
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: debugger
+subjects:
+- kind: Group
+  name: system:serviceaccounts:orchard
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: apple-admin
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: ServiceAccount
+  name: apple
+  namespace: orchard
+`
+
+func TestRBACPrivilegeRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddRbacV1RoleRule(kubelint.RBACV1_ROLE_NO_WILDCARDS, kubelint.RBACV1_ROLE_NO_PRIVILEGE_ESCALATION, kubelint.RBACV1_ROLE_NO_SECRETS_READ, kubelint.RBACV1_ROLE_NO_POD_EXEC)
+	linter.AddRbacV1ClusterRoleRule(kubelint.RBACV1_CLUSTERROLE_NO_WILDCARDS, kubelint.RBACV1_CLUSTERROLE_NO_PRIVILEGE_ESCALATION, kubelint.RBACV1_CLUSTERROLE_NO_SECRETS_READ, kubelint.RBACV1_CLUSTERROLE_NO_POD_EXEC)
+	linter.AddRbacV1RoleBindingRule(kubelint.RBACV1_ROLEBINDING_NOT_CLUSTER_ADMIN)
+	linter.AddRbacV1ClusterRoleBindingRule(kubelint.RBACV1_CLUSTERROLEBINDING_NOT_CLUSTER_ADMIN)
+	results, errs := linter.LintBytes([]byte(privilegesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"RBACV1_ROLE_NO_SECRETS_READ secret-reader: A role shouldn't allow get, list or watch on secrets unless it really has to, since that exposes every credential in its scope", // even for just one secret
+		"RBACV1_CLUSTERROLE_NO_PRIVILEGE_ESCALATION debugger: A cluster role shouldn't allow escalate or bind on roles, or impersonate, since they let you grant yourself more permissions",
+		"RBACV1_CLUSTERROLE_NO_POD_EXEC debugger: A cluster role shouldn't allow pods/exec, since it runs commands inside containers with their credentials",
+		"RBACV1_CLUSTERROLEBINDING_NOT_CLUSTER_ADMIN apple-admin: A cluster role binding shouldn't grant cluster-admin, which allows everything",
+	)
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	ydrs, errs := kubelint.ReadBytes([]byte(privilegesUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Fatal(err)
+	}
+	var resources []*kubelint.Resource
+	for _, ydr := range ydrs {
+		resources = append(resources, &ydr.Resource)
+	}
+	var summaries []string
+	for _, permissions := range kubelint.EffectivePermissions(kubelint.NewResourceGraph(resources)) {
+		summaries = append(summaries, permissions.String())
+	}
+	expected := `ServiceAccount orchard/apple
+  across the cluster: * *.* (ClusterRole cluster-admin via ClusterRoleBinding apple-admin)
+  across the cluster: * nonResourceURLs * (ClusterRole cluster-admin via ClusterRoleBinding apple-admin)
+  in namespace orchard: create */exec (ClusterRole debugger via RoleBinding orchard-debugger)
+  in namespace orchard: bind clusterroles.rbac.authorization.k8s.io (ClusterRole debugger via RoleBinding orchard-debugger)
+ServiceAccount orchard/pear
+  in namespace orchard: get secrets named database (Role secret-reader via RoleBinding pear-secret-reader)
+  in namespace orchard: create */exec (ClusterRole debugger via RoleBinding orchard-debugger)
+  in namespace orchard: bind clusterroles.rbac.authorization.k8s.io (ClusterRole debugger via RoleBinding orchard-debugger)`
+	if summary := strings.Join(summaries, "\n"); summary != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, summary)
+	}
+
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_SERVICE_ACCOUNT_RISKY_PERMISSIONS)
+	results, errs := linter.LintBytes([]byte(privilegesUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	var messages []string
+	for _, result := range results {
+		messages = append(messages, result.Message)
+	}
+	for _, message := range []string{
+		"ServiceAccount orchard/apple can use wildcard verbs or resources across the cluster (ClusterRole cluster-admin via ClusterRoleBinding apple-admin)",
+		"ServiceAccount orchard/pear can read secrets in namespace orchard (Role secret-reader via RoleBinding pear-secret-reader)",
+		"ServiceAccount orchard/pear can exec into pods in namespace orchard (ClusterRole debugger via RoleBinding orchard-debugger)",
+	} {
+		if !strings.Contains(strings.Join(messages, "\n"), message) {
+			t.Errorf("Expected %q, got %v", message, messages)
+		}
+	}
+	if len(results) != 7 {
+		t.Errorf("Expected 4 risky permissions for apple and 3 for pear, got %v", messages)
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const referencesUnit = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: orchard
+data:
+  colour: green
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: orchard
+spec:
+  accessModes: ["ReadWriteOnce"]
+  resources:
+    requests:
+      storage: 1Gi
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: pear:1.0
+        envFrom:
+        - configMapRef:
+            name: migrations
+      containers:
+      - name: app
+        image: pear:1.0
+        envFrom:
+        - configMapRef:
+            name: settings
+        - configMapRef:
+            name: overrides
+            optional: true
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: credentials
+              key: password
+        volumeMounts:
+        - name: cache
+          mountPath: /var/cache/pear
+      volumes:
+      - name: data
+        persistentVolumeClaim:
+          claimName: data
+      - name: cache
+        persistentVolumeClaim:
+          claimName: cache
+      - name: tls
+        secret:
+          secretName: tls
+          optional: true
+`
+
+func TestPodReferencesResolve(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_CONFIGMAP_REFERENCES_EXIST,
+		kubelint.INTERDEPENDENT_SECRET_REFERENCES_EXIST,
+		kubelint.INTERDEPENDENT_PVC_REFERENCES_EXIST,
+	)
+	results, errs := linter.LintBytes([]byte(referencesUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	expected := map[kubelint.RuleID]string{
+		"INTERDEPENDENT_CONFIGMAP_REFERENCES_EXIST": `StatefulSet pear: container "migrate" envFrom[0].configMapRef refers to ConfigMap "migrations", which isn't in the unit`,
+		"INTERDEPENDENT_SECRET_REFERENCES_EXIST":    `StatefulSet pear: container "app" env[PASSWORD].valueFrom.secretKeyRef refers to Secret "credentials", which isn't in the unit`,
+		"INTERDEPENDENT_PVC_REFERENCES_EXIST":       `StatefulSet pear: container "app" volumes[cache].persistentVolumeClaim refers to PersistentVolumeClaim "cache", which isn't in the unit`,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for _, result := range results {
+		if result.Message != expected[result.RuleID] {
+			t.Errorf("Expected %s to report\n%s\ngot\n%s", result.RuleID, expected[result.RuleID], result.Message)
+		}
+		if strings.Contains(result.Message, "overrides") || strings.Contains(result.Message, "tls") {
+			t.Errorf("Optional references shouldn't be reported: %s", result.Message)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const optionsUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      labels:
+        project: orchard
+        team: Fruit
+    spec:
+      securityContext:
+        runAsUser: 1500
+        runAsGroup: 44444
+      containers:
+      - name: ecr
+        image: 123456789012.dkr.ecr.us-east-1.amazonaws.com/pear:1.0
+        resources:
+          requests:
+            cpu: 250m
+          limits:
+            cpu: "2"
+      - name: quay
+        image: quay.io/coreos/etcd:v3.4
+        resources:
+          requests:
+            cpu: "1"
+          limits:
+            cpu: "1"
+      - name: hub
+        image: nginx:1.17
+        resources:
+          requests:
+            cpu: 100m
+          limits:
+            cpu: 100m
+`
+
+// failures lints the options unit with the rules, and gives you the containers (or resources) that failed each rule.
+func failures(t *testing.T, linter *kubelint.Linter) map[kubelint.RuleID][]string {
+	results, errs := linter.LintBytes([]byte(optionsUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	failed := make(map[kubelint.RuleID][]string)
+	for _, result := range results {
+		failed[result.RuleID] = append(failed[result.RuleID], result.Container)
+	}
+	return failed
+}
+
+func TestImageOptions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.NewV1ContainerValidImageRule(kubelint.ImageOptions{
+		AllowedRegistries: []string{"*.dkr.ecr.*.amazonaws.com"},
+		AllowedImages:     []*regexp.Regexp{regexp.MustCompile(`^quay\.io/coreos/`)},
+	}))
+	if failed := failures(t, linter)["V1_CONTAINER_VALID_IMAGE"]; len(failed) != 1 || failed[0] != "hub" {
+		t.Errorf("Expected only the docker hub image to be disallowed, got %v", failed)
+	}
+
+	linter = kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.V1_CONTAINER_VALID_IMAGE)
+	if failed := failures(t, linter)["V1_CONTAINER_VALID_IMAGE"]; len(failed) != 3 {
+		t.Errorf("Expected the default registry to disallow every image, got %v", failed)
+	}
+}
+
+func TestUserGroupIDOptions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.V1_PODSPEC_NON_NIL_SECURITY_CONTEXT, kubelint.NewV1PodSpecUserGroupIDRule(kubelint.UserGroupIDOptions{
+		UserIDs: []kubelint.IDRange{{Min: 1000, Max: 1999}},
+	}))
+	if failed := failures(t, linter); len(failed) != 0 {
+		t.Errorf("Expected user 1500 to be allowed, got %v", failed)
+	}
+
+	linter = kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.V1_PODSPEC_NON_NIL_SECURITY_CONTEXT, kubelint.V1_PODSPEC_CORRECT_USER_GROUP_ID)
+	if failed := failures(t, linter); len(failed["V1_PODSPEC_CORRECT_USER_GROUP_ID"]) != 1 {
+		t.Errorf("Expected the default rule to want user 44444, got %v", failed)
+	}
+	session := linter.NewSession()
+	if _, errs := session.LintBytes([]byte(optionsUnit), "FAKE.yaml"); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	_, fixes := session.ApplyFixes()
+	if len(fixes) != 1 || fixes[0] != "Set pod's User and Group ID to 44444" {
+		t.Errorf("Expected the default fix, got %v", fixes)
+	}
+}
+
+func TestRequiredLabelsOptions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(
+		kubelint.APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL,
+		kubelint.NewAppsV1DeploymentRequiredLabelsRule(kubelint.RequiredLabelsOptions{
+			Labels: []string{"project", "team"},
+			Values: map[string]*regexp.Regexp{"team": regexp.MustCompile(`^[a-z]+$`)},
+		}),
+	)
+	failed := failures(t, linter)
+	if _, found := failed["APPSV1_DEPLOYMENT_EXISTS_PROJECT_LABEL"]; found {
+		t.Errorf("Expected the project label to be found")
+	}
+	if _, found := failed["APPSV1_DEPLOYMENT_REQUIRED_LABELS"]; !found {
+		t.Errorf("Expected the team label not to match its pattern")
+	}
+}
+
+func TestAppK8sLabelFixRenamesAppLabel(t *testing.T) {
+	unit := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      labels:
+        app: pear
+`
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(kubelint.APPSV1_DEPLOYMENT_EXISTS_APP_K8S_LABEL)
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(unit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if len(results) != 1 || results[0].Message != "There should be an app.kubernetes.io/name label present for the deployment's spec.template" {
+		t.Fatalf("Expected the label to be missing, got %v", describeResults(results))
+	}
+	resources, fixes := session.ApplyFixes()
+	if len(fixes) != 1 || fixes[0] != `Found app label in deployment pear and used this value to populate the "app.kubernetes.io/name" key` {
+		t.Errorf("Expected the app label to be renamed, got %v", fixes)
+	}
+	labels := resources[0].Object.(*appsv1.Deployment).Spec.Template.Labels
+	if _, found := labels["app"]; found || labels["app.kubernetes.io/name"] != "pear" {
+		t.Errorf("Expected app to be renamed to app.kubernetes.io/name, got %v", labels)
+	}
+}
+
+func TestCPUOptions(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS, kubelint.NewV1ContainerCPURule(kubelint.CPUOptions{
+		MaxRequest: resource.MustParse("500m"),
+		MaxLimit:   resource.MustParse("1"),
+	}))
+	failed := failures(t, linter)["V1_CONTAINER_REQUESTS_CPU_REASONABLE"]
+	if len(failed) != 2 || failed[0] != "ecr" || failed[1] != "quay" {
+		t.Errorf("Expected ecr's limit and quay's request to be too high, got %v", failed)
+	}
+
+	linter = kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS, kubelint.V1_CONTAINER_REQUESTS_CPU_REASONABLE)
+	if failed := failures(t, linter); len(failed) != 0 {
+		t.Errorf("Expected every request to be at most 1 CPU, got %v", failed)
+	}
+
+	uncapped := kubelint.NewV1ContainerCPURule(kubelint.CPUOptions{})
+	if uncapped.Message != "You should request a reasonable amount of CPU" {
+		t.Errorf("Expected a message that doesn't list any caps, got %q", uncapped.Message)
+	}
+	linter = kubelint.NewDefaultLinter()
+	linter.AddV1ContainerRule(kubelint.V1_CONTAINER_EXISTS_RESOURCE_LIMITS_AND_REQUESTS, uncapped)
+	if failed := failures(t, linter); len(failed) != 0 {
+		t.Errorf("Expected nothing to be checked without any caps, got %v", failed)
+	}
+}
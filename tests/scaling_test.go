@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const scalingUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  replicas: 1
+  template:
+    metadata:
+      labels:
+        app: pear
+    spec:
+      containers:
+      - name: app
+        image: pear:1.0
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: apple
+    spec:
+      containers:
+      - name: app
+        image: apple:1.0
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: apple
+  minReplicas: 2
+  maxReplicas: 5
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: banana
+  namespace: orchard
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: banana
+  minReplicas: 4
+  maxReplicas: 2
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: pear
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  minAvailable: 50%
+  selector:
+    matchLabels:
+      app: apple
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: everything
+  namespace: orchard
+spec:
+  maxUnavailable: 0%
+  selector:
+    matchLabels:
+      app: banana
+`
+
+func TestScalingRules(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAutoscalingV1HorizontalPodAutoscalerRule(kubelint.AUTOSCALINGV1_HPA_MIN_REPLICAS_AT_MOST_MAX)
+	linter.AddAutoscalingV2beta2HorizontalPodAutoscalerRule(kubelint.AUTOSCALINGV2BETA2_HPA_MIN_REPLICAS_AT_MOST_MAX)
+	linter.AddPolicyV1beta1PodDisruptionBudgetRule(kubelint.POLICYV1BETA1_PDB_ALLOWS_EVICTION)
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_HPA_SCALE_TARGET_EXISTS,
+		kubelint.INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET,
+		kubelint.INTERDEPENDENT_PDB_ALLOWS_EVICTION,
+	)
+	results, errs := linter.LintBytes([]byte(scalingUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expectResults(t, results,
+		"AUTOSCALINGV1_HPA_MIN_REPLICAS_AT_MOST_MAX banana: A horizontal pod autoscaler's minReplicas (1 if it's left out) can't be more than its maxReplicas",
+		"POLICYV1BETA1_PDB_ALLOWS_EVICTION everything: A pod disruption budget shouldn't set maxUnavailable to 0 or minAvailable to 100%, since then its pods can never be evicted (eg to drain a node)",
+		"INTERDEPENDENT_HPA_SCALE_TARGET_EXISTS banana: HorizontalPodAutoscaler banana scales Deployment \"banana\", which isn't in the unit",
+		"INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET apple: Deployment apple sets replicas to 3, but it's scaled by HorizontalPodAutoscaler apple, so every apply resets the number of pods it has scaled to",
+		"INTERDEPENDENT_PDB_ALLOWS_EVICTION pear: PodDisruptionBudget pear doesn't allow any of the 1 pods of Deployment pear to be evicted, so nodes running them can't be drained", // one pod, and it has to stay up
+	)
+}
+
+func TestAutoscaledReplicasFix(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_HPA_TARGET_REPLICAS_UNSET)
+	session := linter.NewSession()
+	if _, errs := session.LintBytes([]byte(scalingUnit), "FAKE.yaml"); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	resources, fixes := session.ApplyFixes()
+	if len(fixes) != 1 || fixes[0] != "Removed replicas from Deployment apple, since HorizontalPodAutoscaler apple scales it" {
+		t.Errorf("Expected apple's replicas to be removed, got %v", fixes)
+	}
+	if deployment := resources[1].Object.(*appsv1.Deployment); deployment.Spec.Replicas != nil {
+		t.Errorf("Expected apple's replicas to be cleared, got %d", *deployment.Spec.Replicas)
+	}
+	if deployment := resources[0].Object.(*appsv1.Deployment); deployment.Spec.Replicas == nil {
+		t.Errorf("Expected pear's replicas to be left alone")
+	}
+}
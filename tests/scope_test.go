@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+	log "github.com/sirupsen/logrus"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const clusterScopedUnit = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: orchard
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: pear-reader
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: fast
+provisioner: kubernetes.io/no-provisioner
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: apple
+  namespace: orchard
+`
+
+func TestNamespaceFixLeavesClusterScopedResourcesAlone(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_MATCHING_NAMESPACE)
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(clusterScopedUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	if len(results) != 1 || results[0].Resources[0].Resource.Object.GetName() != "pear" {
+		t.Fatalf("Expected only the deployment to be reported, got %v", describeResults(results))
+	}
+	resources, _ := session.ApplyFixes()
+	for _, resource := range resources {
+		namespace := resource.Object.GetNamespace()
+		switch resource.TypeInfo.GetKind() {
+		case "ClusterRole", "Namespace", "StorageClass":
+			if namespace != "" {
+				t.Errorf("%s %s is cluster scoped, but was put in namespace %s", resource.TypeInfo.GetKind(), resource.Object.GetName(), namespace)
+			}
+		default:
+			if namespace != "orchard" {
+				t.Errorf("Expected %s to be moved into orchard", resource.Object.GetName())
+			}
+		}
+	}
+}
+
+func TestInterdependentFixIsGivenOffendingResources(t *testing.T) {
+	var fixed []string
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(&kubelint.InterdependentRule{
+		ID: "DEPLOYMENTS_NAMESPACED",
+		Condition: func(resources []*kubelint.Resource) (bool, []*kubelint.Resource) {
+			var offending []*kubelint.Resource
+			for _, resource := range resources {
+				if resource.TypeInfo.GetKind() == "Deployment" && resource.Object.GetNamespace() == "" {
+					offending = append(offending, resource)
+				}
+			}
+			return len(offending) == 0, offending
+		},
+		Message: "Deployments should be namespaced",
+		Level:   log.ErrorLevel,
+		FixOffending: func(resources []*kubelint.Resource, offending []*kubelint.Resource) bool {
+			for _, resource := range offending {
+				fixed = append(fixed, resource.Object.GetName())
+				resource.Object.SetNamespace("orchard")
+			}
+			return true
+		},
+	})
+	session := linter.NewSession()
+	_, errs := session.LintBytes([]byte(clusterScopedUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	session.ApplyFixes()
+	if len(fixed) != 1 || fixed[0] != "pear" {
+		t.Errorf("Expected the fix to be given just pear, got %v", fixed)
+	}
+}
+
+func TestRESTMapperDecidesWhatsNamespaced(t *testing.T) {
+	// pretend Deployments are cluster scoped, the mapper doesn't know about anything else
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeRoot)
+	linter := kubelint.NewDefaultLinter()
+	linter.SetRESTMapper(mapper)
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_MATCHING_NAMESPACE)
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(clusterScopedUnit), "FAKE.yaml")
+	for _, err := range supportedErrors(errs) {
+		t.Error(err)
+	}
+	// the ClusterRole and StorageClass fall back to the built-in table, so nothing is reported
+	if len(results) != 0 {
+		t.Errorf("Expected the deployment to be treated as cluster scoped, got %v", describeResults(results))
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const serviceUnit = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  template:
+    metadata:
+      labels:
+        app: pear
+    spec:
+      containers:
+      - name: app
+        image: pear:1.0
+        ports:
+        - name: http
+          containerPort: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+  ports:
+  - port: 80
+    targetPort: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear-by-number
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+  ports:
+  - port: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: wrong-port
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+  ports:
+  - port: 80
+    targetPort: 9090
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: apple
+  namespace: orchard
+spec:
+  selector:
+    app: apple
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear-elsewhere
+  namespace: greenhouse
+spec:
+  selector:
+    app: pear
+  ports:
+  - port: 80
+    targetPort: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: external
+  namespace: orchard
+spec:
+  type: ExternalName
+  externalName: example.com
+`
+
+func TestServiceSelectorsMatchWorkloads(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(
+		kubelint.INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD,
+		kubelint.INTERDEPENDENT_SERVICE_TARGET_PORT_EXISTS,
+	)
+	results, errs := linter.LintBytes([]byte(serviceUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	offending := make(map[kubelint.RuleID][]string)
+	for _, result := range results {
+		for _, resource := range result.Resources {
+			offending[result.RuleID] = append(offending[result.RuleID], resource.Resource.Object.GetName())
+		}
+	}
+	if names := offending["INTERDEPENDENT_SERVICE_SELECTOR_MATCHES_WORKLOAD"]; len(names) != 2 || names[0] != "apple" || names[1] != "pear-elsewhere" {
+		t.Errorf("Expected apple and pear-elsewhere to select nothing, got %v", names)
+	}
+	if names := offending["INTERDEPENDENT_SERVICE_TARGET_PORT_EXISTS"]; len(names) != 1 || names[0] != "wrong-port" {
+		t.Errorf("Expected wrong-port to have a missing targetPort, got %v", names)
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+func TestLinterDoesNotAccumulateResources(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddV1PodSpecRule(kubelint.V1_PODSPEC_NON_NIL_SECURITY_CONTEXT)
+	deployment := []byte(`kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: pear
+`)
+	for i := 0; i < 3; i++ {
+		results, errs := linter.LintBytes(deployment, "FAKE_DEPLOYMENT.yaml")
+		for _, err := range errs {
+			t.Error(err)
+		}
+		if len(results) != 1 {
+			t.Errorf("Expected every Lint call to start afresh, got %v", describeResults(results))
+		}
+	}
+	session := linter.NewSession()
+	if _, errs := session.LintBytes(deployment, "FAKE_DEPLOYMENT.yaml"); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	resources, fixDescriptions := session.ApplyFixes()
+	if len(resources) != 1 || len(fixDescriptions) != 1 {
+		t.Errorf("Expected the session's one resource to be fixed, got %d resources and %v", len(resources), fixDescriptions)
+	}
+	_, fixDescriptions = session.ApplyFixes()
+	if len(fixDescriptions) != 0 {
+		t.Errorf("Fixes shouldn't be applied twice, got %v", fixDescriptions)
+	}
+}
+
+func TestLintingConcurrentlyWithOneLinter(t *testing.T) {
+	linter := newBenchmarkLinter()
+	data := manyDeployments(10)
+	expected, errs := linter.LintBytes(data, "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session := linter.NewSession()
+			results, errs := session.LintBytes(data, "FAKE.yaml")
+			for _, err := range errs {
+				t.Error(err)
+			}
+			if len(results) != len(expected) {
+				t.Errorf("Expected %d results, got %d", len(expected), len(results))
+			}
+			if _, fixDescriptions := session.ApplyFixes(); len(fixDescriptions) == 0 {
+				t.Errorf("Expected each session to fix its own resources")
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if results, _ := linter.LintBytes(data, "FAKE.yaml"); len(results) != len(expected) {
+				t.Errorf("Expected %d results, got %d", len(expected), len(results))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSessionsAreIndependent(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE)
+	first := linter.NewSession()
+	second := linter.NewSession()
+	_, errs := first.LintBytes([]byte(`kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: pear
+`), "FIRST.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	_, errs = second.LintBytes([]byte(`kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: apple
+  namespace: orchard
+`), "SECOND.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if len(first.Results()) != 1 {
+		t.Errorf("Expected 1 result in the first session, got %d", len(first.Results()))
+	}
+	if len(second.Results()) != 0 {
+		t.Errorf("Expected no results in the second session, got %d", len(second.Results()))
+	}
+	if len(first.Resources()) != 1 || first.Resources()[0].Object.GetName() != "pear" {
+		t.Errorf("The first session should only own the pear deployment")
+	}
+}
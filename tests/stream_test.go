@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const helmOutput = `---
+# Source: chart/templates/namespace.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: orchard
+---
+# Source: chart/templates/empty.yaml
+---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+  namespace: orchard
+spec:
+  selector:
+    app: pear
+`
+
+func TestReadStreamLineNumbers(t *testing.T) {
+	var resources []*kubelint.YamlDerivedResource
+	errs := kubelint.ReadStream(strings.NewReader(helmOutput), "FAKE.yaml", func(resource *kubelint.YamlDerivedResource) bool {
+		resources = append(resources, resource)
+		return true
+	})
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if len(resources) != 3 {
+		t.Fatalf("Expected 3 resources, got %d", len(resources))
+	}
+	expectedLines := []int{3, 11, 16}
+	for i, resource := range resources {
+		if resource.LineNumber != expectedLines[i] {
+			t.Errorf("Expected %s to be on line %d, got %d", resource.Resource.Object.GetName(), expectedLines[i], resource.LineNumber)
+		}
+	}
+}
+
+func TestLintStream(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE)
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_MATCHING_NAMESPACE)
+	var results []*kubelint.Result
+	errs := linter.LintStream(context.Background(), strings.NewReader(helmOutput), "FAKE.yaml", func(result *kubelint.Result) {
+		results = append(results, result)
+	})
+	for _, err := range errs {
+		t.Error(err)
+	}
+	var ruleIDs []string
+	for _, result := range results {
+		ruleIDs = append(ruleIDs, string(result.RuleID))
+	}
+	expected := "APPSV1_DEPLOYMENT_WITHIN_NAMESPACE INTERDEPENDENT_MATCHING_NAMESPACE"
+	if strings.Join(ruleIDs, " ") != expected {
+		t.Errorf("Expected results from %s, got %v", expected, ruleIDs)
+	}
+}
+
+func TestLintReader(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddAppsV1DeploymentRule(kubelint.APPSV1_DEPLOYMENT_WITHIN_NAMESPACE)
+	results, errors := linter.LintReader(context.Background(), bytes.NewBufferString(helmOutput), "FAKE.yaml")
+	count := 0
+	for result := range results {
+		t.Log(result.Message)
+		count++
+	}
+	for _, err := range <-errors {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 result, got %d", count)
+	}
+}
+
+func TestLintReaderStopsOnceCancelled(t *testing.T) {
+	linter := newBenchmarkLinter()
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errors := linter.LintReader(ctx, bytes.NewReader(manyDeployments(50)), "FAKE.yaml")
+	// stop reading after the first result, the linting shouldn't carry on waiting for us
+	<-results
+	cancel()
+	select {
+	case <-errors:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the linting to give up once the context was cancelled")
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/CoverGenius/kubelint"
+)
+
+const wrongNamespaceUnit = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: orchard
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+  namespace: apple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pear
+`
+
+func TestViolationsAreReportedSeparately(t *testing.T) {
+	linter := kubelint.NewDefaultLinter()
+	linter.AddInterdependentRule(kubelint.INTERDEPENDENT_MATCHING_NAMESPACE)
+	session := linter.NewSession()
+	results, errs := session.LintBytes([]byte(wrongNamespaceUnit), "FAKE.yaml")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	expected := []string{
+		"Deployment pear is in namespace apple but the unit's namespace is orchard",
+		"Service pear has no namespace but the unit's namespace is orchard",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), describeResults(results))
+	}
+	for i, result := range results {
+		if result.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], result.Message)
+		}
+		if len(result.Resources) != 1 || result.Resources[0].LineNumber == 0 {
+			t.Errorf("Expected each result to point at the resource it's about")
+		}
+	}
+	resources, descriptions := session.ApplyFixes()
+	if len(descriptions) != 1 || descriptions[0] != "Moved Deployment pear into namespace orchard; Moved Service pear into namespace orchard" {
+		t.Errorf("Unexpected fix descriptions %v", descriptions)
+	}
+	for _, resource := range resources[1:] {
+		if resource.Object.GetNamespace() != "orchard" {
+			t.Errorf("Expected %s to be moved into orchard", resource.Object.GetName())
+		}
+	}
+}
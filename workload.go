@@ -0,0 +1,157 @@
+package kubelint
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	v1beta1Extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podTemplate finds the metadata and spec of the pods that a workload (a Deployment, StatefulSet, DaemonSet, ReplicaSet,
+// ReplicationController, Job or CronJob) creates. A Pod is its own template.
+// The last return value is false if the object doesn't create any pods.
+func podTemplate(object metav1.Object) (*metav1.ObjectMeta, *v1.PodSpec, bool) {
+	switch workload := object.(type) {
+	case *v1.Pod:
+		return &workload.ObjectMeta, &workload.Spec, true
+	case *appsv1.Deployment:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *appsv1.StatefulSet:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *appsv1.DaemonSet:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *appsv1.ReplicaSet:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *v1beta1Extensions.Deployment:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *v1beta1Extensions.DaemonSet:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *v1beta1Extensions.ReplicaSet:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *v1.ReplicationController:
+		if workload.Spec.Template == nil {
+			return nil, nil, false
+		}
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *batchV1.Job:
+		return &workload.Spec.Template.ObjectMeta, &workload.Spec.Template.Spec, true
+	case *batchV1beta1.CronJob:
+		return &workload.Spec.JobTemplate.Spec.Template.ObjectMeta, &workload.Spec.JobTemplate.Spec.Template.Spec, true
+	}
+	return nil, nil, false
+}
+
+// replicas finds the spec.replicas field of a workload that runs a number of identical pods (a Deployment, StatefulSet,
+// ReplicaSet or ReplicationController), so you can read it or clear it. The field is nil when replicas is left out,
+// in which case Kubernetes runs one pod. The last return value is false if the object doesn't have replicas.
+func replicas(object metav1.Object) (**int32, bool) {
+	switch workload := object.(type) {
+	case *appsv1.Deployment:
+		return &workload.Spec.Replicas, true
+	case *appsv1.StatefulSet:
+		return &workload.Spec.Replicas, true
+	case *appsv1.ReplicaSet:
+		return &workload.Spec.Replicas, true
+	case *v1beta1Extensions.Deployment:
+		return &workload.Spec.Replicas, true
+	case *v1beta1Extensions.ReplicaSet:
+		return &workload.Spec.Replicas, true
+	case *v1.ReplicationController:
+		return &workload.Spec.Replicas, true
+	}
+	return nil, false
+}
+
+// podReference is a reference from a pod spec to a ConfigMap, Secret or PersistentVolumeClaim.
+type podReference struct {
+	kind      string // ConfigMap, Secret or PersistentVolumeClaim
+	name      string // the name of the resource being referred to
+	container string // the container the reference is in, or empty if it's in a volume
+	field     string // where the reference is, eg envFrom[0].configMapRef or env[PASSWORD].valueFrom.secretKeyRef
+	optional  bool   // true if the pod can start without the resource
+}
+
+func (r podReference) String() string {
+	if r.container == "" {
+		return fmt.Sprintf("%s refers to %s %q", r.field, r.kind, r.name)
+	}
+	return fmt.Sprintf("container %q %s refers to %s %q", r.container, r.field, r.kind, r.name)
+}
+
+// podReferences finds every ConfigMap, Secret and PersistentVolumeClaim the pod spec refers to,
+// through the environment of its containers (init containers included) and its volumes.
+// A volume's references are attributed to each container that mounts it, or to no container if none of them do.
+func podReferences(podSpec *v1.PodSpec) []podReference {
+	var references []podReference
+	containers := append(append([]v1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	mountedBy := make(map[string][]string) // the names of the containers that mount each volume
+	for _, container := range containers {
+		for _, mount := range container.VolumeMounts {
+			mountedBy[mount.Name] = append(mountedBy[mount.Name], container.Name)
+		}
+		for _, device := range container.VolumeDevices {
+			mountedBy[device.Name] = append(mountedBy[device.Name], container.Name)
+		}
+		for i, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				references = append(references, podReference{"ConfigMap", ref.Name, container.Name, fmt.Sprintf("envFrom[%d].configMapRef", i), isOptional(ref.Optional)})
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				references = append(references, podReference{"Secret", ref.Name, container.Name, fmt.Sprintf("envFrom[%d].secretRef", i), isOptional(ref.Optional)})
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				references = append(references, podReference{"ConfigMap", ref.Name, container.Name, fmt.Sprintf("env[%s].valueFrom.configMapKeyRef", env.Name), isOptional(ref.Optional)})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				references = append(references, podReference{"Secret", ref.Name, container.Name, fmt.Sprintf("env[%s].valueFrom.secretKeyRef", env.Name), isOptional(ref.Optional)})
+			}
+		}
+	}
+	for _, volume := range podSpec.Volumes {
+		field := fmt.Sprintf("volumes[%s]", volume.Name)
+		var volumeReferences []podReference
+		if source := volume.ConfigMap; source != nil {
+			volumeReferences = append(volumeReferences, podReference{"ConfigMap", source.Name, "", field + ".configMap", isOptional(source.Optional)})
+		}
+		if source := volume.Secret; source != nil {
+			volumeReferences = append(volumeReferences, podReference{"Secret", source.SecretName, "", field + ".secret", isOptional(source.Optional)})
+		}
+		if source := volume.PersistentVolumeClaim; source != nil {
+			volumeReferences = append(volumeReferences, podReference{"PersistentVolumeClaim", source.ClaimName, "", field + ".persistentVolumeClaim", false})
+		}
+		if volume.Projected != nil {
+			for i, projection := range volume.Projected.Sources {
+				if source := projection.ConfigMap; source != nil {
+					volumeReferences = append(volumeReferences, podReference{"ConfigMap", source.Name, "", fmt.Sprintf("%s.projected.sources[%d].configMap", field, i), isOptional(source.Optional)})
+				}
+				if source := projection.Secret; source != nil {
+					volumeReferences = append(volumeReferences, podReference{"Secret", source.Name, "", fmt.Sprintf("%s.projected.sources[%d].secret", field, i), isOptional(source.Optional)})
+				}
+			}
+		}
+		if len(mountedBy[volume.Name]) == 0 {
+			references = append(references, volumeReferences...)
+			continue
+		}
+		for _, container := range mountedBy[volume.Name] {
+			for _, reference := range volumeReferences {
+				reference.container = container
+				references = append(references, reference)
+			}
+		}
+	}
+	return references
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}